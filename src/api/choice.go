@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+// parseNamedValues splits "name:value" entries from one or more
+// comma-separated query values. The value is taken after the last ':'
+// so names may contain colons; entries without a value get defaultValue.
+func parseNamedValues(lists []string, defaultValue string) (names []string, values []string, err error) {
+	for _, list := range lists {
+		for _, entry := range strings.Split(list, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+
+			name, value := entry, defaultValue
+			if i := strings.LastIndex(entry, ":"); i >= 0 {
+				name, value = strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+			}
+			if name == "" {
+				return nil, nil, errors.New("empty name")
+			}
+			if value == "" {
+				return nil, nil, errors.New("missing value for " + name)
+			}
+
+			names = append(names, name)
+			values = append(values, value)
+		}
+	}
+	return names, values, nil
+}
+
+func (h *Handlers) RandomChoice(c *gin.Context) {
+	const maxItems = 1000
+	const maxPicks = 1000
+
+	names, weightStrs, err := parseNamedValues(c.QueryArray("items"), "1")
+	if err != nil || len(names) == 0 || len(names) > maxItems {
+		responder{c}.err(http.StatusBadRequest,
+			"Items must be a comma-separated list of name:weight pairs (1 to 1000 items).")
+		return
+	}
+
+	weights, err := rng.ParseWeights(weightStrs)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	k, err := strconv.Atoi(c.DefaultQuery("k", "1"))
+	if err != nil || k < 1 || k > maxPicks {
+		responder{c}.err(http.StatusBadRequest, "Invalid k.")
+		return
+	}
+
+	replace, err := strconv.ParseBool(c.DefaultQuery("replace", "true"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid replace flag.")
+		return
+	}
+
+	if !replace {
+		positive := 0
+		for _, w := range weights {
+			if w > 0 {
+				positive++
+			}
+		}
+		if k > positive {
+			responder{c}.err(http.StatusBadRequest,
+				"There are more items to pick than items with a positive weight.")
+			return
+		}
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+	if count*k > maxBatchValues {
+		responder{c}.err(http.StatusBadRequest, "k times count may not exceed 100000.")
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([][]string, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			indices, err := rng.WeightedChoice(h.r, h.health, weights, k, replace)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random choice."
+			}
+
+			picked := make([]string, len(indices))
+			for j, idx := range indices {
+				picked[j] = names[idx]
+			}
+			results = append(results, picked)
+
+			// Names cannot contain commas, so a batch lists each pick on one line.
+			sep := "\n"
+			if batch {
+				sep = ", "
+			}
+			lines = append(lines, strings.Join(picked, sep))
+		}
+
+		items := make([]gin.H, len(names))
+		for i := range names {
+			items[i] = gin.H{"name": names[i], "weight": weightStrs[i]}
+		}
+
+		payload := gin.H{"items": items, "k": k, "replace": replace}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["picked"] = results[0]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
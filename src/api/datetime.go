@@ -0,0 +1,169 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomDateTime(c *gin.Context) {
+	start, err := time.Parse(time.RFC3339, c.DefaultQuery("start", "2000-01-01T00:00:00Z"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid start (expected RFC 3339).")
+		return
+	}
+
+	end := time.Now().UTC()
+	if endStr, ok := c.GetQuery("end"); ok {
+		if end, err = time.Parse(time.RFC3339, endStr); err != nil {
+			responder{c}.err(http.StatusBadRequest, "Invalid end (expected RFC 3339).")
+			return
+		}
+	}
+
+	loc, err := time.LoadLocation(c.DefaultQuery("tz", "UTC"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid tz (expected an IANA time zone name).")
+		return
+	}
+
+	weekdays, err := strconv.ParseBool(c.DefaultQuery("weekdays", "false"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid weekdays flag.")
+		return
+	}
+
+	businessHours, err := strconv.ParseBool(c.DefaultQuery("business_hours", "false"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid business_hours flag.")
+		return
+	}
+
+	spec := rng.DateTimeSpec{
+		Start:         start,
+		End:           end,
+		Granularity:   c.DefaultQuery("granularity", "second"),
+		Location:      loc,
+		Weekdays:      weekdays,
+		BusinessHours: businessHours,
+	}
+
+	sampler, err := rng.NewDateTimeSampler(spec)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	layout := time.RFC3339
+	switch spec.Granularity {
+	case "day":
+		layout = time.DateOnly
+	case "millisecond":
+		layout = "2006-01-02T15:04:05.000Z07:00"
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]gin.H, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			t, err := sampler.Sample(h.r, h.health)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random instant."
+			}
+			formatted := t.Format(layout)
+			results = append(results, gin.H{"datetime": formatted, "unix_ms": t.UnixMilli()})
+			lines = append(lines, formatted)
+		}
+
+		payload := gin.H{
+			"start":          start.Format(time.RFC3339Nano),
+			"end":            end.Format(time.RFC3339Nano),
+			"granularity":    spec.Granularity,
+			"tz":             loc.String(),
+			"weekdays":       weekdays,
+			"business_hours": businessHours,
+		}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["datetime"] = results[0]["datetime"]
+			payload["unix_ms"] = results[0]["unix_ms"]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
+
+func (h *Handlers) RandomDuration(c *gin.Context) {
+	min, err := time.ParseDuration(c.DefaultQuery("min", "0s"))
+	if err != nil || min < 0 {
+		responder{c}.err(http.StatusBadRequest, "Invalid min duration.")
+		return
+	}
+
+	max, err := time.ParseDuration(c.DefaultQuery("max", "1h"))
+	if err != nil || max < 0 {
+		responder{c}.err(http.StatusBadRequest, "Invalid max duration.")
+		return
+	}
+
+	granularity := c.DefaultQuery("granularity", "millisecond")
+	unit, ok := map[string]time.Duration{
+		"nanosecond":  time.Nanosecond,
+		"microsecond": time.Microsecond,
+		"millisecond": time.Millisecond,
+		"second":      time.Second,
+		"minute":      time.Minute,
+		"hour":        time.Hour,
+	}[granularity]
+	if !ok {
+		responder{c}.err(http.StatusBadRequest,
+			"Granularity must be nanosecond, microsecond, millisecond, second, minute or hour.")
+		return
+	}
+
+	if err := rng.ValidateDuration(min, max, unit); err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]gin.H, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			d, err := rng.RandomDuration(h.r, h.health, min, max, unit)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random duration."
+			}
+			results = append(results, gin.H{"duration": d.String(), "nanoseconds": d.Nanoseconds()})
+			lines = append(lines, d.String())
+		}
+
+		payload := gin.H{"min": min.String(), "max": max.String(), "granularity": granularity}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["duration"] = results[0]["duration"]
+			payload["nanoseconds"] = results[0]["nanoseconds"]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
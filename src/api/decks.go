@@ -0,0 +1,302 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+// DeckTTLFromEnv returns how long an untouched /decks deck is kept.
+// Configurable via DECK_TTL as a Go duration (default 1h).
+func DeckTTLFromEnv() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("DECK_TTL")); err == nil && d > 0 {
+		return d
+	}
+	return time.Hour
+}
+
+// MaxDecksFromEnv returns how many /decks decks may be open at once.
+// Configurable via MAX_DECKS (default 1000).
+func MaxDecksFromEnv() int {
+	if n, err := strconv.Atoi(os.Getenv("MAX_DECKS")); err == nil && n > 0 {
+		return n
+	}
+	return 1000
+}
+
+// maxDeckCards bounds decks × cards per deck for /cards and /decks.
+const maxDeckCards = 100000
+
+// deckConfigFromRequest reads the deck type (built-in name, or "custom" with a
+// JSON definition in the `definition` param or the POST body), decks, jokers
+// and reversals params. It writes the error response itself.
+func deckConfigFromRequest(c *gin.Context) (rng.DeckConfig, bool) {
+	name := c.DefaultQuery("type", "standard")
+	def, ok := rng.LookupDeckType(name)
+	if name == "custom" {
+		data := []byte(c.Query("definition"))
+		if c.Request.Method == http.MethodPost && len(data) == 0 {
+			body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+			if err != nil {
+				responder{c}.err(http.StatusBadRequest, "request body too large or unreadable")
+				return rng.DeckConfig{}, false
+			}
+			data = body
+		}
+		var err error
+		if def, err = rng.ParseDeckType(data); err != nil {
+			responder{c}.err(http.StatusBadRequest, err.Error())
+			return rng.DeckConfig{}, false
+		}
+	} else if !ok {
+		responder{c}.err(http.StatusBadRequest,
+			"Type must be custom or one of "+strings.Join(rng.DeckTypeNames(), ", ")+".")
+		return rng.DeckConfig{}, false
+	}
+
+	numDecks, err := strconv.Atoi(c.DefaultQuery("decks", "1"))
+	if err != nil || numDecks < 1 || numDecks > 100 {
+		responder{c}.err(http.StatusBadRequest, "Invalid deck count.")
+		return rng.DeckConfig{}, false
+	}
+
+	jokers, err := strconv.ParseBool(c.DefaultQuery("jokers", "false"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid jokers flag.")
+		return rng.DeckConfig{}, false
+	}
+	if jokers && len(def.Jokers) == 0 {
+		responder{c}.err(http.StatusBadRequest, "This deck type has no jokers.")
+		return rng.DeckConfig{}, false
+	}
+
+	reversals, err := strconv.ParseBool(c.DefaultQuery("reversals", "true"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid reversals flag.")
+		return rng.DeckConfig{}, false
+	}
+
+	if numDecks*def.Size(jokers) > maxDeckCards {
+		responder{c}.err(http.StatusBadRequest,
+			fmt.Sprintf("Decks may hold at most %d cards in total.", maxDeckCards))
+		return rng.DeckConfig{}, false
+	}
+
+	return rng.DeckConfig{
+		Type:       def.Name,
+		Decks:      numDecks,
+		Jokers:     jokers,
+		Reversals:  reversals && def.Reversible,
+		Definition: def,
+	}, true
+}
+
+// cardFormatFromQuery reads `format` (default name). It writes the error
+// response itself.
+func cardFormatFromQuery(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", rng.FormatName)
+	if !slices.Contains(rng.CardFormats, format) {
+		responder{c}.err(http.StatusBadRequest, "Format must be "+strings.Join(rng.CardFormats, ", ")+".")
+		return "", false
+	}
+	return format, true
+}
+
+func unsupportedFormat(format string) string {
+	return fmt.Sprintf("Format %s is not available for this deck type.", format)
+}
+
+// formattedCard is a card in JSON output together with its rendering in the
+// requested format.
+type formattedCard struct {
+	rng.Card
+	Code      string `json:"code,omitempty"`
+	Glyph     string `json:"glyph,omitempty"`
+	Rank      int    `json:"rank,omitempty"`
+	SuitIndex int    `json:"suit_index,omitempty"`
+}
+
+// formatCards renders cards one per line for text output, and as JSON items
+// that carry the format's field. With the index format, payload also gets
+// the rank and suit lists the indices point into.
+func formatCards(def *rng.DeckType, format string, cards []rng.Card, payload gin.H) (string, any) {
+	lines := make([]string, len(cards))
+	for i, c := range cards {
+		lines[i] = def.FormatCard(c, format)
+	}
+	if format == rng.FormatName {
+		return strings.Join(lines, "\n"), cards
+	}
+
+	items := make([]formattedCard, len(cards))
+	for i, c := range cards {
+		items[i].Card = c
+		switch format {
+		case rng.FormatCode:
+			items[i].Code = lines[i]
+		case rng.FormatGlyph:
+			items[i].Glyph = lines[i]
+		case rng.FormatIndex:
+			items[i].Rank, items[i].SuitIndex = def.Indices(c)
+		}
+	}
+	if format == rng.FormatIndex {
+		payload["ranks"], payload["suits"] = def.RankNames(), def.SuitNames()
+	}
+	return strings.Join(lines, "\n"), items
+}
+
+// deckError maps deck store errors to a status and message.
+func deckError(err error) (int, string) {
+	switch {
+	case errors.Is(err, rng.ErrDeckNotFound):
+		return http.StatusNotFound, "Deck not found."
+	case errors.Is(err, rng.ErrNotEnoughCards):
+		return http.StatusBadRequest, "There are more cards to pick than cards in the deck."
+	case errors.Is(err, rng.ErrCardNotDealt):
+		return http.StatusBadRequest, "Only cards dealt from this deck can be returned."
+	case errors.Is(err, rng.ErrTooManyDecks):
+		return http.StatusServiceUnavailable, "Too many open decks; delete one or try again later."
+	}
+	return http.StatusInternalServerError, "Error shuffling the deck."
+}
+
+func deckStateText(s rng.DeckState) string {
+	return fmt.Sprintf("deck %s: %d remaining, %d dealt, %d discarded", s.ID, s.Remaining, s.Dealt, s.Discarded)
+}
+
+func (h *Handlers) CreateDeck(c *gin.Context) {
+	config, ok := deckConfigFromRequest(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		state, err := h.decks.Create(h.r, h.health, config)
+		if err != nil {
+			status, msg := deckError(err)
+			return "", nil, status, msg
+		}
+		return deckStateText(state), gin.H{"deck": state}, 0, ""
+	})
+}
+
+// GetDeck and DeleteDeck read no entropy, so they answer even while the
+// RNG is unhealthy.
+func (h *Handlers) GetDeck(c *gin.Context) {
+	state, err := h.decks.State(c.Param("id"))
+	if err != nil {
+		responder{c}.err(deckError(err))
+		return
+	}
+	responder{c}.ok(deckStateText(state), gin.H{"deck": state}, "deck-state")
+}
+
+func (h *Handlers) DeleteDeck(c *gin.Context) {
+	if err := h.decks.Delete(c.Param("id")); err != nil {
+		responder{c}.err(deckError(err))
+		return
+	}
+	responder{c}.ok("deleted", gin.H{"deleted": c.Param("id")}, "deck-delete")
+}
+
+// deckCardsHandler serves draw, peek and burn, which all take `cards` and
+// `format`.
+func (h *Handlers) deckCardsHandler(op func(id string, n int) ([]rng.Card, rng.DeckState, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		n, err := strconv.Atoi(c.DefaultQuery("cards", "1"))
+		if err != nil || n < 1 {
+			responder{c}.err(http.StatusBadRequest, "Invalid card count.")
+			return
+		}
+
+		format, ok := cardFormatFromQuery(c)
+		if !ok {
+			return
+		}
+
+		h.handleRNG(c, func() (string, gin.H, int, string) {
+			state, err := h.decks.State(c.Param("id"))
+			if err == nil && !state.Config.Definition.SupportsFormat(format) {
+				return "", nil, http.StatusBadRequest, unsupportedFormat(format)
+			}
+			var cards []rng.Card
+			if err == nil {
+				cards, state, err = op(c.Param("id"), n)
+			}
+			if err != nil {
+				status, msg := deckError(err)
+				return "", nil, status, msg
+			}
+
+			payload := gin.H{"deck": state}
+			text, items := formatCards(state.Config.Definition, format, cards, payload)
+			payload["cards"] = items
+			return text, payload, 0, ""
+		})
+	}
+}
+
+func (h *Handlers) DrawDeck(c *gin.Context) { h.deckCardsHandler(h.decks.Draw)(c) }
+func (h *Handlers) PeekDeck(c *gin.Context) { h.deckCardsHandler(h.decks.Peek)(c) }
+func (h *Handlers) BurnDeck(c *gin.Context) { h.deckCardsHandler(h.decks.Burn)(c) }
+
+// ReturnDeck takes the cards to return as a JSON array in the body, as card
+// objects or in any card format, or all=true to return every dealt card.
+func (h *Handlers) ReturnDeck(c *gin.Context) {
+	all, err := strconv.ParseBool(c.DefaultQuery("all", "false"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid all flag.")
+		return
+	}
+
+	var body []byte
+	if !all {
+		if body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes)); err != nil {
+			responder{c}.err(http.StatusBadRequest, "Request body too large or unreadable.")
+			return
+		}
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		var cards []rng.Card
+		if !all {
+			state, err := h.decks.State(c.Param("id"))
+			if err != nil {
+				status, msg := deckError(err)
+				return "", nil, status, msg
+			}
+			if cards, err = state.Config.Definition.DecodeCards(body); err != nil {
+				return "", nil, http.StatusBadRequest, err.Error() + "; or pass all=true"
+			}
+		}
+
+		state, err := h.decks.Return(c.Param("id"), cards, all)
+		if err != nil {
+			status, msg := deckError(err)
+			return "", nil, status, msg
+		}
+		return deckStateText(state), gin.H{"deck": state}, 0, ""
+	})
+}
+
+func (h *Handlers) ShuffleDeck(c *gin.Context) {
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		state, err := h.decks.Shuffle(h.r, h.health, c.Param("id"))
+		if err != nil {
+			status, msg := deckError(err)
+			return "", nil, status, msg
+		}
+		return deckStateText(state), gin.H{"deck": state}, 0, ""
+	})
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomDice(c *gin.Context) {
+	exprStr := c.DefaultQuery("expr", "1d6")
+
+	expr, err := rng.ParseDice(exprStr)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		var expression string
+		results := make([]gin.H, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			res, err := expr.Roll(h.r, h.health)
+			if errors.Is(err, rng.ErrTooManyDice) || errors.Is(err, rng.ErrDiceOutOfRange) {
+				return "", nil, http.StatusBadRequest, err.Error()
+			}
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random die."
+			}
+			expression = res.Expression
+			results = append(results, gin.H{"terms": res.Terms, "total": res.Total})
+
+			text := diceText(res)
+			if batch {
+				text = strings.ReplaceAll(text, "\n", "; ")
+			}
+			lines = append(lines, text)
+		}
+
+		payload := gin.H{"expression": expression}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["terms"] = results[0]["terms"]
+			payload["total"] = results[0]["total"]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
+
+// diceText lists each term's dice, marking exploded (!) and dropped (in
+// parentheses) ones, followed by the total.
+func diceText(res *rng.DiceResult) string {
+	var out strings.Builder
+	for _, t := range res.Terms {
+		if len(t.Dice) == 0 {
+			continue
+		}
+		out.WriteString(t.Notation + ":")
+		for _, d := range t.Dice {
+			v := strconv.Itoa(d.Value)
+			if d.Exploded {
+				v += "!"
+			}
+			if !d.Kept {
+				v = "(" + v + ")"
+			}
+			out.WriteString(" " + v)
+		}
+		out.WriteString(" = " + strconv.Itoa(t.Value) + "\n")
+	}
+	out.WriteString("Total: " + strconv.Itoa(res.Total))
+	return out.String()
+}
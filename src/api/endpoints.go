@@ -1,12 +1,16 @@
 package api
 
 import (
-	"bytes"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 
@@ -14,8 +18,21 @@ import (
 )
 
 func (h *Handlers) RandomBytes(c *gin.Context) {
-	const maxSize = 256
+	const maxTextBytes = 1 << 20    // per request, across the whole batch
+	const maxBinaryBytes = 16 << 20 // streamed, never buffered whole
+
+	encoding := c.DefaultQuery("encoding", "hex")
+	encode, ok := byteEncoders[encoding]
+	if !ok && encoding != "binary" {
+		responder{c}.err(http.StatusBadRequest,
+			"Encoding must be hex, base64, base64url, base32, bits or binary.")
+		return
+	}
 
+	maxSize := maxTextBytes
+	if encoding == "binary" {
+		maxSize = maxBinaryBytes
+	}
 	sizeVar := c.DefaultQuery("size", "1")
 	size, err := strconv.Atoi(sizeVar)
 	if err != nil || size < 1 || size > maxSize {
@@ -24,21 +41,120 @@ func (h *Handlers) RandomBytes(c *gin.Context) {
 		return
 	}
 
+	if encoding == "binary" {
+		if _, batch := c.GetQuery("count"); batch {
+			responder{c}.err(http.StatusBadRequest, "Count is not supported with binary encoding.")
+			return
+		}
+		h.streamBytes(c, size)
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+	if size*count > maxTextBytes {
+		responder{c}.err(http.StatusBadRequest,
+			fmt.Sprintf("Size times count must not exceed %d bytes.", maxTextBytes))
+		return
+	}
+
 	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]string, 0, count)
 		buf := make([]byte, size)
-		if _, err := io.ReadFull(h.r, buf); err != nil {
-			if h.health != nil {
-				h.health.Set(false, "error fetching random bytes: "+err.Error())
+		for i := 0; i < count; i++ {
+			if _, err := io.ReadFull(h.r, buf); err != nil {
+				if h.health != nil {
+					h.health.Set(false, "error fetching random bytes: "+err.Error())
+				}
+				h.log.Error(err)
+				return "", nil, http.StatusInternalServerError, "Error fetching random bytes."
 			}
-			h.log.Error(err)
-			return "", nil, http.StatusInternalServerError, "Error fetching random bytes."
+			results = append(results, encode(buf))
 		}
 
-		hex := fmt.Sprintf("%x", buf)
-		return hex, gin.H{"bytes": hex, "size": size}, 0, ""
+		if batch {
+			return strings.Join(results, "\n"),
+				gin.H{"size": size, "encoding": encoding, "count": count, "results": results}, 0, ""
+		}
+		out := results[0]
+		return out, gin.H{"bytes": out, "size": size, "encoding": encoding}, 0, ""
 	})
 }
 
+var byteEncoders = map[string]func([]byte) string{
+	"hex":       hex.EncodeToString,
+	"base64":    base64.StdEncoding.EncodeToString,
+	"base64url": base64.RawURLEncoding.EncodeToString,
+	"base32":    base32.StdEncoding.EncodeToString,
+	"bits": func(b []byte) string {
+		var out strings.Builder
+		out.Grow(8 * len(b))
+		for _, v := range b {
+			out.WriteString(fmt.Sprintf("%08b", v))
+		}
+		return out.String()
+	},
+}
+
+// streamBytes writes size raw bytes as a chunked application/octet-stream.
+// The first chunk is read before anything is sent, so an RNG that is already
+// failing still gets a normal error response. The request id is only known
+// once every byte has been read, so it is sent as the X-Request-Id trailer;
+// a failure mid-stream ends the body early with an X-Error trailer instead.
+func (h *Handlers) streamBytes(c *gin.Context, size int) {
+	const chunkSize = 32 << 10
+
+	if !h.rngOK(c) {
+		return
+	}
+
+	fail := func(err error) {
+		if h.health != nil {
+			h.health.Set(false, "error fetching random bytes: "+err.Error())
+		}
+		h.log.Error(err)
+	}
+
+	buf := make([]byte, min(chunkSize, size))
+	if _, err := io.ReadFull(h.r, buf); err != nil {
+		fail(err)
+		responder{c}.err(http.StatusInternalServerError, "Error fetching random bytes.")
+		return
+	}
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Trailer", "X-Request-Id, X-Error")
+	w.WriteHeader(http.StatusOK)
+
+	for remaining := size; remaining > 0; {
+		if _, err := w.Write(buf); err != nil {
+			return // client went away
+		}
+		w.Flush()
+		remaining -= len(buf)
+		if remaining == 0 {
+			break
+		}
+
+		buf = buf[:min(chunkSize, remaining)]
+		if _, err := io.ReadFull(h.r, buf); err != nil {
+			fail(err)
+			w.Header().Set("X-Error", "Error fetching random bytes.")
+			return
+		}
+	}
+
+	requestID, err := h.uuidFromRNG()
+	if err != nil {
+		w.Header().Set("X-Error", "Error generating request id.")
+		return
+	}
+	w.Header().Set("X-Request-Id", requestID)
+}
+
 func (h *Handlers) RandomNumber(c *gin.Context) {
 	min, err := strconv.Atoi(c.DefaultQuery("min", "1"))
 	if err != nil {
@@ -52,28 +168,37 @@ func (h *Handlers) RandomNumber(c *gin.Context) {
 		return
 	}
 
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
 	h.handleRNG(c, func() (string, gin.H, int, string) {
-		n, err := rng.UniformInt32(h.r, h.health, min, max)
-		if err != nil {
-			return "", nil, http.StatusBadRequest, err.Error()
+		results := make([]int32, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			n, err := rng.UniformInt32(h.r, h.health, min, max)
+			if err != nil {
+				return "", nil, http.StatusBadRequest, err.Error()
+			}
+			results = append(results, n)
+			lines = append(lines, fmt.Sprintf("%d", n))
 		}
 
-		return fmt.Sprintf("%d", n),
-			gin.H{"number": n, "min": min, "max": max},
+		if batch {
+			return strings.Join(lines, "\n"),
+				gin.H{"min": min, "max": max, "count": count, "results": results},
+				0, ""
+		}
+		return lines[0],
+			gin.H{"number": results[0], "min": min, "max": max},
 			0, ""
 	})
 }
 
 func (h *Handlers) RandomCards(c *gin.Context) {
-	numDecks, err := strconv.Atoi(c.DefaultQuery("decks", "1"))
-	if err != nil || numDecks < 1 || numDecks > 100 {
-		responder{c}.err(http.StatusBadRequest, "Invalid deck count.")
-		return
-	}
-
-	jokers, err := strconv.ParseBool(c.DefaultQuery("jokers", "false"))
-	if err != nil {
-		responder{c}.err(http.StatusBadRequest, "Invalid jokers flag.")
+	config, ok := deckConfigFromRequest(c)
+	if !ok {
 		return
 	}
 
@@ -83,47 +208,49 @@ func (h *Handlers) RandomCards(c *gin.Context) {
 		return
 	}
 
+	format, ok := cardFormatFromQuery(c)
+	if !ok {
+		return
+	}
+	if !config.Definition.SupportsFormat(format) {
+		responder{c}.err(http.StatusBadRequest, unsupportedFormat(format))
+		return
+	}
+
 	h.handleRNG(c, func() (string, gin.H, int, string) {
-		deck := rng.AddDeck(numDecks, jokers)
-		if numCards > len(deck) {
+		deck := config.Definition.Build(1, config.Jokers)
+		if numCards > len(deck)*config.Decks {
 			return "", nil, http.StatusBadRequest,
 				"There are more cards to pick than cards in the deck."
 		}
 
-		picked := make([]rng.Card, 0, numCards)
-		for i := 0; i < numCards; i++ {
-			index := int32(0)
-			if len(deck) > 1 {
-				var err error
-				index, err = rng.UniformInt32(h.r, h.health, 0, len(deck)-1)
-				if err != nil {
-					return "", nil, http.StatusInternalServerError,
-						"Error fetching a random card."
-				}
-			}
-			picked = append(picked, deck[int(index)])
-			deck = rng.RemoveCard(deck, int(index))
+		picked, err := rng.DrawCards(h.r, h.health, deck, config.Decks, numCards)
+		if err != nil {
+			return "", nil, http.StatusInternalServerError,
+				"Error fetching a random card."
 		}
-
-		var out bytes.Buffer
-		for i, c := range picked {
-			out.WriteString(c.Value + " of " + c.Suit)
-			if i < len(picked)-1 {
-				out.WriteByte('\n')
+		if config.Reversals {
+			if err := rng.Orient(h.r, h.health, picked); err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random card."
 			}
 		}
 
-		return out.String(), gin.H{
-			"decks":  numDecks,
-			"jokers": jokers,
+		payload := gin.H{
+			"type":   config.Type,
+			"decks":  config.Decks,
+			"jokers": config.Jokers,
 			"cards":  numCards,
-			"drawn":  picked,
-		}, 0, ""
+		}
+		text, drawn := formatCards(config.Definition, format, picked, payload)
+		payload["drawn"] = drawn
+		return text, payload, 0, ""
 	})
 }
 
 func (h *Handlers) RandomStrings(c *gin.Context) {
 	const maxSize = 256
+	const maxCharset = 4096
 
 	size, err := strconv.Atoi(c.DefaultQuery("size", "10"))
 	if err != nil || size < 1 || size > maxSize {
@@ -155,66 +282,174 @@ func (h *Handlers) RandomStrings(c *gin.Context) {
 		return
 	}
 
-	if !lowers && !uppers && !numbers && !symbols {
-		responder{c}.err(http.StatusBadRequest, "At least one flag must be set.")
+	excludeAmbiguous, err := strconv.ParseBool(c.DefaultQuery("exclude_ambiguous", "false"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid exclude_ambiguous flag.")
 		return
 	}
 
-	h.handleRNG(c, func() (string, gin.H, int, string) {
-		charset := rng.BuildCharset(lowers, uppers, numbers, symbols)
-		var out bytes.Buffer
-		out.Grow(size)
+	// A custom charset replaces the four class flags.
+	custom := c.Query("charset")
+	if len(custom) > maxCharset || !utf8.ValidString(custom) {
+		responder{c}.err(http.StatusBadRequest, "Invalid charset.")
+		return
+	}
+	var charset []rune
+	if custom != "" {
+		charset = []rune(custom)
+	} else {
+		if !lowers && !uppers && !numbers && !symbols {
+			responder{c}.err(http.StatusBadRequest, "At least one flag must be set.")
+			return
+		}
+		charset = []rune(string(rng.BuildCharset(lowers, uppers, numbers, symbols)))
+	}
+
+	exclude := c.Query("exclude")
+	if excludeAmbiguous {
+		exclude += rng.AmbiguousChars
+	}
+	charset = rng.FilterCharset(charset, exclude)
+	if len(charset) == 0 {
+		responder{c}.err(http.StatusBadRequest, "Charset is empty after exclusions.")
+		return
+	}
+
+	var mins [rng.NumClasses]int
+	for _, m := range []struct {
+		class int
+		name  string
+	}{
+		{rng.ClassLower, "min_lowercase"},
+		{rng.ClassUpper, "min_uppercase"},
+		{rng.ClassDigit, "min_numbers"},
+		{rng.ClassSymbol, "min_symbols"},
+	} {
+		n, err := strconv.Atoi(c.DefaultQuery(m.name, "0"))
+		if err != nil || n < 0 || n > maxSize {
+			responder{c}.err(http.StatusBadRequest, "Invalid "+m.name+".")
+			return
+		}
+		mins[m.class] = n
+	}
+
+	sampler, err := rng.NewStringSampler(charset, size, mins)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
 
-		for i := 0; i < size; i++ {
-			index, err := rng.UniformInt32(h.r, h.health, 0, len(charset)-1)
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]string, 0, count)
+		for n := 0; n < count; n++ {
+			s, err := sampler.Generate(h.r, h.health)
 			if err != nil {
 				return "", nil, http.StatusInternalServerError,
 					"Error fetching a random character."
 			}
-			out.WriteByte(charset[int(index)])
-		}
-
-		s := out.String()
-		return s, gin.H{
-			"string":    s,
-			"size":      size,
-			"lowercase": lowers,
-			"uppercase": uppers,
-			"numbers":   numbers,
-			"symbols":   symbols,
-		}, 0, ""
+			results = append(results, s)
+		}
+
+		payload := gin.H{
+			"size":          size,
+			"charset_size":  len(charset),
+			"min_lowercase": mins[rng.ClassLower],
+			"min_uppercase": mins[rng.ClassUpper],
+			"min_numbers":   mins[rng.ClassDigit],
+			"min_symbols":   mins[rng.ClassSymbol],
+			"entropy_bits":  sampler.EntropyBits(),
+		}
+		if custom != "" {
+			payload["charset"] = string(charset)
+		} else {
+			payload["lowercase"] = lowers
+			payload["uppercase"] = uppers
+			payload["numbers"] = numbers
+			payload["symbols"] = symbols
+		}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["string"] = results[0]
+		}
+		return strings.Join(results, "\n"), payload, 0, ""
 	})
 }
 
 func (h *Handlers) RandomPercent(c *gin.Context) {
-	percentStr := c.DefaultQuery("percent", "25")
+	// Exactly one of percent / p / odds; percent=25 when none is given.
+	param, value, parse := "percent", "25", rng.ParsePercentProbability
+	given := 0
+	for _, opt := range []struct {
+		name  string
+		parse func(string) (rng.Probability, error)
+	}{
+		{"percent", rng.ParsePercentProbability},
+		{"p", rng.ParseFractionProbability},
+		{"odds", rng.ParseOddsProbability},
+	} {
+		if v, ok := c.GetQuery(opt.name); ok {
+			param, value, parse = opt.name, v, opt.parse
+			given++
+		}
+	}
+	if given > 1 {
+		responder{c}.err(http.StatusBadRequest, "Use only one of percent, p or odds.")
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
 
 	h.handleRNG(c, func() (string, gin.H, int, string) {
-		target, den, err := rng.ParsePercentExact(percentStr)
+		prob, err := parse(value)
 		if err != nil {
 			return "", nil, http.StatusBadRequest, err.Error()
 		}
 
-		roll, err := rng.UniformInt32(h.r, h.health, 1, den)
-		if err != nil {
-			return "", nil, http.StatusInternalServerError,
-				"Error fetching a random number."
-		}
+		results := make([]gin.H, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			roll, pass, err := prob.Roll(h.r, h.health)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random number."
+			}
+
+			result := "Fail"
+			if pass {
+				result = "Pass"
+			}
+			results = append(results, gin.H{"roll": roll, "pass": pass})
 
-		pass := int(roll) <= target
-		result := "Fail"
-		if pass {
-			result = "Pass"
+			sep := "\n"
+			if batch {
+				sep = ": "
+			}
+			lines = append(lines, fmt.Sprintf("Rolled %s from %s/%s%s%s", roll, prob.Num, prob.Den, sep, result))
 		}
 
-		text := fmt.Sprintf("Rolled %d from %d/%d\n%s", roll, target, den, result)
-		return text, gin.H{
-			"percent": percentStr,
-			"target":  target,
-			"out_of":  den,
-			"roll":    int(roll),
-			"pass":    pass,
-		}, 0, ""
+		payload := gin.H{
+			param:    value,
+			"target": prob.Num,
+			"out_of": prob.Den,
+		}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["roll"] = results[0]["roll"]
+			payload["pass"] = results[0]["pass"]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
 	})
 }
 
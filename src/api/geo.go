@@ -0,0 +1,205 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+// parseFinite parses a float, rejecting NaN and ±Inf, which ParseFloat
+// accepts but no coordinate or radius can be.
+func parseFinite(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	return f, true
+}
+
+// parseFloats parses a comma-separated list of exactly n finite floats.
+func parseFloats(s string, n int) ([]float64, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, false
+	}
+	out := make([]float64, n)
+	for i, p := range parts {
+		f, ok := parseFinite(p)
+		if !ok {
+			return nil, false
+		}
+		out[i] = f
+	}
+	return out, true
+}
+
+func (h *Handlers) RandomGeo(c *gin.Context) {
+	mode := c.DefaultQuery("mode", "sphere")
+
+	var sample func() (rng.LatLon, error)
+	switch mode {
+	case "sphere":
+		sample = func() (rng.LatLon, error) { return rng.RandomOnSphere(h.r, h.health) }
+
+	case "bbox":
+		b, ok := parseFloats(c.Query("bbox"), 4)
+		if !ok {
+			responder{c}.err(http.StatusBadRequest, "bbox must be south,west,north,east.")
+			return
+		}
+		if err := rng.ValidateBBox(b[0], b[1], b[2], b[3]); err != nil {
+			responder{c}.err(http.StatusBadRequest, err.Error())
+			return
+		}
+		sample = func() (rng.LatLon, error) { return rng.RandomInBBox(h.r, h.health, b[0], b[1], b[2], b[3]) }
+
+	case "radius":
+		lat, ok1 := parseFinite(c.Query("lat"))
+		lon, ok2 := parseFinite(c.Query("lon"))
+		radius, ok3 := parseFinite(c.Query("radius"))
+		if !ok1 || !ok2 || !ok3 {
+			responder{c}.err(http.StatusBadRequest, "radius mode needs lat, lon and radius (meters).")
+			return
+		}
+		center := rng.LatLon{Lat: lat, Lon: lon}
+		if err := rng.ValidateRadius(center, radius); err != nil {
+			responder{c}.err(http.StatusBadRequest, err.Error())
+			return
+		}
+		sample = func() (rng.LatLon, error) { return rng.RandomInRadius(h.r, h.health, center, radius) }
+
+	case "polygon":
+		data := []byte(c.Query("polygon"))
+		if c.Request.Method == http.MethodPost {
+			body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+			if err != nil {
+				responder{c}.err(http.StatusBadRequest, "request body too large or unreadable")
+				return
+			}
+			data = body
+		}
+		poly, err := rng.ParseGeoJSONPolygon(data)
+		if err != nil {
+			responder{c}.err(http.StatusBadRequest, err.Error())
+			return
+		}
+		sampler, err := rng.NewPolygonSampler(poly)
+		if err != nil {
+			responder{c}.err(http.StatusBadRequest, err.Error())
+			return
+		}
+		sample = func() (rng.LatLon, error) { return sampler.Sample(h.r, h.health) }
+
+	default:
+		responder{c}.err(http.StatusBadRequest, "Mode must be sphere, bbox, radius or polygon.")
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		points := make([]rng.LatLon, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			pt, err := sample()
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random point."
+			}
+			points = append(points, pt)
+			lines = append(lines, fmt.Sprintf("%.7f,%.7f", pt.Lat, pt.Lon))
+		}
+
+		payload := gin.H{"mode": mode}
+		if batch {
+			payload["count"] = count
+			payload["results"] = points
+		} else {
+			payload["lat"] = points[0].Lat
+			payload["lon"] = points[0].Lon
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
+
+func (h *Handlers) RandomPoint(c *gin.Context) {
+	shape := c.DefaultQuery("shape", "sphere")
+
+	dim, err := strconv.Atoi(c.DefaultQuery("dim", "3"))
+	if err != nil || dim < 1 || dim > rng.MaxPointDimension {
+		responder{c}.err(http.StatusBadRequest,
+			fmt.Sprintf("Dim must be an integer between 1 and %d.", rng.MaxPointDimension))
+		return
+	}
+
+	radius, ok := parseFinite(c.DefaultQuery("radius", "1"))
+	if !ok || radius <= 0 {
+		responder{c}.err(http.StatusBadRequest, "Invalid radius.")
+		return
+	}
+
+	var sample func() ([]float64, error)
+	switch shape {
+	case "sphere":
+		sample = func() ([]float64, error) {
+			v, err := rng.RandomUnitVector(h.r, h.health, dim)
+			for i := range v {
+				v[i] *= radius
+			}
+			return v, err
+		}
+	case "ball":
+		sample = func() ([]float64, error) { return rng.RandomInBall(h.r, h.health, dim, radius) }
+	case "simplex":
+		sample = func() ([]float64, error) { return rng.RandomInSimplex(h.r, h.health, dim) }
+	default:
+		responder{c}.err(http.StatusBadRequest, "Shape must be sphere, ball or simplex.")
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		points := make([][]float64, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			p, err := sample()
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random point."
+			}
+			points = append(points, p)
+
+			coords := make([]string, len(p))
+			for j, x := range p {
+				coords[j] = strconv.FormatFloat(x, 'g', -1, 64)
+			}
+			lines = append(lines, strings.Join(coords, ","))
+		}
+
+		payload := gin.H{"shape": shape, "dim": dim}
+		if shape != "simplex" {
+			payload["radius"] = radius
+		}
+		if batch {
+			payload["count"] = count
+			payload["results"] = points
+		} else {
+			payload["point"] = points[0]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
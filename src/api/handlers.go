@@ -1,9 +1,12 @@
 package api
 
 import (
+	"crypto/subtle"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -12,13 +15,24 @@ import (
 )
 
 type Handlers struct {
-	r      io.Reader
-	health *rng.Health
-	log    *zap.SugaredLogger
+	r        io.Reader
+	health   *rng.Health
+	log      *zap.SugaredLogger
+	maxCount int
+	ulid     rng.ULIDGenerator // shared so monotonic ULIDs stay ordered across requests
+	streams  chan struct{}     // semaphore bounding concurrent /stream subscribers
+	decks    *rng.DeckStore
 }
 
 func NewHandlers(r io.Reader, h *rng.Health, log *zap.SugaredLogger) *Handlers {
-	return &Handlers{r: r, health: h, log: log}
+	return &Handlers{
+		r:        r,
+		health:   h,
+		log:      log,
+		maxCount: MaxCountFromEnv(),
+		streams:  make(chan struct{}, MaxStreamsFromEnv()),
+		decks:    rng.NewDeckStore(DeckTTLFromEnv(), MaxDecksFromEnv()),
+	}
 }
 
 func (h *Handlers) rngOK(c *gin.Context) bool {
@@ -77,6 +91,33 @@ func (h *Handlers) handleRNG(
 
 func APIKeyFromEnv() string { return os.Getenv("API_KEY") }
 
+// MaxCountFromEnv returns the largest accepted `count` for batch requests.
+// Configurable via MAX_BATCH_COUNT (default 1000).
+func MaxCountFromEnv() int {
+	if n, err := strconv.Atoi(os.Getenv("MAX_BATCH_COUNT")); err == nil && n > 0 {
+		return n
+	}
+	return 1000
+}
+
+// batchCount reads the optional `count` query param.
+// batch reports whether it was given, so that single-outcome responses keep
+// their original shape. It writes the error response itself.
+func (h *Handlers) batchCount(c *gin.Context) (count int, batch bool, ok bool) {
+	countStr, batch := c.GetQuery("count")
+	if !batch {
+		return 1, false, true
+	}
+
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 1 || count > h.maxCount {
+		responder{c}.err(http.StatusBadRequest,
+			fmt.Sprintf("Count must be an integer between 1 and %d.", h.maxCount))
+		return 0, false, false
+	}
+	return count, true, true
+}
+
 func CheckHeader(headerName, expectedValue string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Auth disabled if not configured
@@ -92,3 +133,21 @@ func CheckHeader(headerName, expectedValue string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequireHeader is CheckHeader for sensitive endpoints: it also rejects every
+// request when no expected value is configured, instead of disabling auth.
+func RequireHeader(headerName, expectedValue string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if expectedValue == "" {
+			responder{c}.err(http.StatusForbidden, "This endpoint requires API_KEY to be configured.")
+			c.Abort()
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader(headerName)), []byte(expectedValue)) != 1 {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		c.Next()
+	}
+}
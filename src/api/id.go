@@ -0,0 +1,113 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomID(c *gin.Context) {
+	const maxSize = 256
+	const maxAlphabet = 1024
+
+	kind := c.DefaultQuery("type", "uuid4")
+	payload := gin.H{"type": kind}
+
+	var next func() (string, error)
+	var entropy float64
+	switch kind {
+	case "uuid4":
+		next = h.uuidFromRNG
+		entropy = 122
+
+	case "uuid7":
+		next = func() (string, error) { return rng.NewUUIDv7(h.r, h.health, time.Now()) }
+		entropy = 74
+
+	case "ulid":
+		monotonic, err := strconv.ParseBool(c.DefaultQuery("monotonic", "true"))
+		if err != nil {
+			responder{c}.err(http.StatusBadRequest, "Invalid monotonic flag.")
+			return
+		}
+		next = func() (string, error) { return h.ulid.Next(h.r, h.health, time.Now(), monotonic) }
+		entropy = 80
+		payload["monotonic"] = monotonic
+
+	case "nanoid":
+		size, err := strconv.Atoi(c.DefaultQuery("size", "21"))
+		if err != nil || size < 1 || size > maxSize {
+			responder{c}.err(http.StatusBadRequest, "Invalid size.")
+			return
+		}
+		alphabet := c.DefaultQuery("alphabet", rng.NanoIDAlphabet)
+		if len(alphabet) > maxAlphabet || !utf8.ValidString(alphabet) {
+			responder{c}.err(http.StatusBadRequest, "Invalid alphabet.")
+			return
+		}
+		runes := rng.FilterCharset([]rune(alphabet), "")
+		if len(runes) < 2 {
+			responder{c}.err(http.StatusBadRequest, "Alphabet needs at least 2 distinct characters.")
+			return
+		}
+		sampler, err := rng.NewStringSampler(runes, size, [rng.NumClasses]int{})
+		if err != nil {
+			responder{c}.err(http.StatusBadRequest, err.Error())
+			return
+		}
+		next = func() (string, error) { return sampler.Generate(h.r, h.health) }
+		entropy = sampler.EntropyBits()
+		payload["size"] = size
+		payload["alphabet"] = string(runes)
+
+	case "base32", "base64url":
+		n, err := strconv.Atoi(c.DefaultQuery("bytes", "16"))
+		if err != nil || n < 1 || n > maxSize {
+			responder{c}.err(http.StatusBadRequest, "Invalid bytes.")
+			return
+		}
+		next = func() (string, error) { return rng.RandomToken(h.r, h.health, n, kind) }
+		entropy = float64(8 * n)
+		payload["bytes"] = n
+
+	default:
+		responder{c}.err(http.StatusBadRequest,
+			"Type must be uuid4, uuid7, ulid, nanoid, base32 or base64url.")
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			id, err := next()
+			if errors.Is(err, rng.ErrULIDOverflow) {
+				return "", nil, http.StatusServiceUnavailable, "ULID space for this millisecond is exhausted; retry."
+			}
+			if err != nil {
+				return "", nil, http.StatusInternalServerError, "Error fetching a random id."
+			}
+			results = append(results, id)
+		}
+
+		payload["entropy_bits"] = entropy
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["id"] = results[0]
+		}
+		return strings.Join(results, "\n"), payload, 0, ""
+	})
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomKeys(c *gin.Context) {
+	// Never let private keys land in a shared or browser cache.
+	c.Header("Cache-Control", "no-store")
+
+	kind := c.DefaultQuery("type", "ed25519")
+
+	defaultFormat := "pem"
+	if kind == "wireguard" {
+		defaultFormat = "base64"
+	}
+	format := c.DefaultQuery("format", defaultFormat)
+	switch format {
+	case "pem", "openssh", "base64":
+	default:
+		responder{c}.err(http.StatusBadRequest, "Format must be pem, openssh or base64.")
+		return
+	}
+
+	switch kind {
+	case "ed25519", "x25519", "wireguard", "p256", "p384", "rsa":
+	default:
+		responder{c}.err(http.StatusBadRequest, "Type must be ed25519, x25519, wireguard, p256, p384 or rsa.")
+		return
+	}
+	if format == "openssh" && (kind == "x25519" || kind == "wireguard") {
+		responder{c}.err(http.StatusBadRequest, "OpenSSH format is not available for x25519 keys.")
+		return
+	}
+
+	bits, err := strconv.Atoi(c.DefaultQuery("bits", "3072"))
+	if err != nil || (bits != 2048 && bits != 3072 && bits != 4096) {
+		responder{c}.err(http.StatusBadRequest, "Bits must be 2048, 3072 or 4096.")
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		key, err := rng.GenerateKeyPair(h.r, h.health, kind, bits)
+		if err != nil {
+			return "", nil, http.StatusInternalServerError,
+				"Error generating a key pair."
+		}
+		private, public, err := key.Encode(format)
+		if err != nil {
+			return "", nil, http.StatusInternalServerError,
+				"Error encoding the key pair."
+		}
+
+		payload := gin.H{
+			"type":        kind,
+			"format":      format,
+			"private_key": private,
+			"public_key":  public,
+		}
+		if kind == "rsa" {
+			payload["bits"] = bits
+		}
+		return strings.TrimSpace(private) + "\n" + strings.TrimSpace(public), payload, 0, ""
+	})
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomLottery(c *gin.Context) {
+	const maxBalls = 1000
+
+	sorted, err := strconv.ParseBool(c.DefaultQuery("sorted", "true"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid sorted flag.")
+		return
+	}
+
+	preset := c.Query("preset")
+	var pools []rng.LotteryPool
+	if preset != "" {
+		var ok bool
+		pools, ok = rng.LotteryPreset(preset)
+		if !ok {
+			responder{c}.err(http.StatusBadRequest,
+				"Unknown preset. Use 6/49, powerball or euromillions.")
+			return
+		}
+	} else {
+		main, ok := lotteryPoolFromQuery(c, "main", "", "picks", "1", "49", "6")
+		if !ok {
+			return
+		}
+		if main.Count < 1 {
+			responder{c}.err(http.StatusBadRequest, "Invalid picks value.")
+			return
+		}
+		pools = append(pools, main)
+
+		bonus, ok := lotteryPoolFromQuery(c, "bonus", "bonus_", "bonus", "1", "10", "0")
+		if !ok {
+			return
+		}
+		if bonus.Count > 0 {
+			pools = append(pools, bonus)
+		}
+	}
+
+	picks := 0
+	for _, p := range pools {
+		if p.Count > maxBalls || p.Count > p.Max-p.Min+1 {
+			responder{c}.err(http.StatusBadRequest,
+				"There are more numbers to pick than numbers in the range.")
+			return
+		}
+		picks += p.Count
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+	if count*picks > maxBatchValues {
+		responder{c}.err(http.StatusBadRequest, "The numbers picked times count may not exceed 100000.")
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		tickets := make([][]gin.H, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			draws, err := rng.DrawLottery(h.r, h.health, pools)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random number."
+			}
+
+			poolLines := make([]string, len(pools))
+			results := make([]gin.H, len(pools))
+			for j, p := range pools {
+				numbers := draws[j]
+				if sorted {
+					sort.Ints(numbers)
+				}
+
+				parts := make([]string, len(numbers))
+				for k, n := range numbers {
+					parts[k] = strconv.Itoa(n)
+				}
+				poolLines[j] = p.Name + ": " + strings.Join(parts, " ")
+				results[j] = gin.H{"pool": p.Name, "min": p.Min, "max": p.Max, "numbers": numbers}
+			}
+			tickets = append(tickets, results)
+
+			sep := "\n"
+			if batch {
+				sep = " | "
+			}
+			lines = append(lines, strings.Join(poolLines, sep))
+		}
+
+		payload := gin.H{"sorted": sorted}
+		if preset != "" {
+			payload["preset"] = preset
+		}
+		if batch {
+			payload["count"] = count
+			payload["results"] = tickets
+		} else {
+			payload["draws"] = tickets[0]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
+
+// lotteryPoolFromQuery reads min/max and the pick count for one pool, using
+// prefix to namespace the range params. It writes the error response itself.
+func lotteryPoolFromQuery(c *gin.Context, name, prefix, countKey, defMin, defMax, defCount string) (rng.LotteryPool, bool) {
+	min, err := strconv.Atoi(c.DefaultQuery(prefix+"min", defMin))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid "+prefix+"min value.")
+		return rng.LotteryPool{}, false
+	}
+
+	max, err := strconv.Atoi(c.DefaultQuery(prefix+"max", defMax))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid "+prefix+"max value.")
+		return rng.LotteryPool{}, false
+	}
+
+	count, err := strconv.Atoi(c.DefaultQuery(countKey, defCount))
+	if err != nil || count < 0 {
+		responder{c}.err(http.StatusBadRequest, "Invalid "+countKey+" value.")
+		return rng.LotteryPool{}, false
+	}
+
+	if min < -1_000_000_000 || max > 1_000_000_000 || min > max {
+		responder{c}.err(http.StatusBadRequest,
+			"min and max must be between -1,000,000,000 and 1,000,000,000 with min <= max")
+		return rng.LotteryPool{}, false
+	}
+
+	return rng.LotteryPool{Name: name, Min: min, Max: max, Count: count}, true
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+// RandomMnemonic takes the optional passphrase from a POST body,
+// {"passphrase": "..."}, so it stays out of URLs and request logs.
+func (h *Handlers) RandomMnemonic(c *gin.Context) {
+	// Seed phrases are secrets; keep them out of caches.
+	c.Header("Cache-Control", "no-store")
+
+	words, err := strconv.Atoi(c.DefaultQuery("words", "12"))
+	if err != nil || words < 12 || words > 24 || words%3 != 0 {
+		responder{c}.err(http.StatusBadRequest, "Words must be 12, 15, 18, 21 or 24.")
+		return
+	}
+
+	if _, ok := c.GetQuery("passphrase"); ok {
+		responder{c}.err(http.StatusBadRequest, "Send the passphrase in a POST body, not the query string.")
+		return
+	}
+	var body struct {
+		Passphrase *string `json:"passphrase"`
+	}
+	if c.Request.Method == http.MethodPost {
+		data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+		if err != nil {
+			responder{c}.err(http.StatusBadRequest, "Request body too large or unreadable.")
+			return
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &body); err != nil {
+				responder{c}.err(http.StatusBadRequest, `Body must be a JSON object such as {"passphrase": "..."}.`)
+				return
+			}
+		}
+	}
+
+	// A passphrase implies seed derivation; seed=true derives with an empty one.
+	passphrase, hasPassphrase := "", body.Passphrase != nil
+	if hasPassphrase {
+		passphrase = *body.Passphrase
+	}
+	withSeed, err := strconv.ParseBool(c.DefaultQuery("seed", strconv.FormatBool(hasPassphrase)))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid seed flag.")
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		mnemonic, entropy, err := rng.NewMnemonic(h.r, h.health, words)
+		if err != nil {
+			return "", nil, http.StatusInternalServerError,
+				"Error fetching random entropy."
+		}
+
+		text := mnemonic
+		payload := gin.H{
+			"words":    words,
+			"mnemonic": mnemonic,
+			"entropy":  hex.EncodeToString(entropy),
+		}
+		if withSeed {
+			seed := hex.EncodeToString(rng.MnemonicSeed(mnemonic, passphrase))
+			payload["seed"] = seed
+			text += "\nseed: " + seed
+		}
+		return text, payload, 0, ""
+	})
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomMAC(c *gin.Context) {
+	var prefix []byte
+	if p := c.Query("prefix"); p != "" {
+		// Accept "00:1a:2b", "00-1a-2b" or "001a2b".
+		hexStr := strings.NewReplacer(":", "", "-", "", ".", "").Replace(p)
+		if len(hexStr)%2 != 0 || len(hexStr) > 10 {
+			responder{c}.err(http.StatusBadRequest, "Prefix must be up to 5 hex octets.")
+			return
+		}
+		for i := 0; i < len(hexStr); i += 2 {
+			b, err := strconv.ParseUint(hexStr[i:i+2], 16, 8)
+			if err != nil {
+				responder{c}.err(http.StatusBadRequest, "Prefix must be up to 5 hex octets.")
+				return
+			}
+			prefix = append(prefix, byte(b))
+		}
+	}
+	if len(prefix) > 0 && prefix[0]&0x01 != 0 {
+		responder{c}.err(http.StatusBadRequest, "Prefix must be unicast (first octet even).")
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			mac, err := rng.RandomMAC(h.r, h.health, prefix)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching random bytes."
+			}
+			results = append(results, mac.String())
+		}
+
+		payload := gin.H{}
+		if len(prefix) > 0 {
+			payload["prefix"] = net.HardwareAddr(prefix).String()
+		}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["mac"] = results[0]
+		}
+		return strings.Join(results, "\n"), payload, 0, ""
+	})
+}
+
+func (h *Handlers) RandomIP(c *gin.Context) {
+	prefix, err := netip.ParsePrefix(c.DefaultQuery("cidr", "10.0.0.0/8"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid cidr.")
+		return
+	}
+	prefix = prefix.Masked()
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			addr, err := rng.RandomIP(h.r, h.health, prefix)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random address."
+			}
+			results = append(results, addr.String())
+		}
+
+		payload := gin.H{"cidr": prefix.String()}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["ip"] = results[0]
+		}
+		return strings.Join(results, "\n"), payload, 0, ""
+	})
+}
+
+// parsePortList parses "80,443,8000-8100" into a set.
+func parsePortList(s string) (map[int]bool, bool) {
+	set := map[int]bool{}
+	if s == "" {
+		return set, true
+	}
+	for _, part := range strings.Split(s, ",") {
+		lo, hi, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		a, err := strconv.Atoi(lo)
+		if err != nil || a < 1 || a > 65535 {
+			return nil, false
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(hi); err != nil || b < a || b > 65535 {
+				return nil, false
+			}
+		}
+		for p := a; p <= b; p++ {
+			set[p] = true
+		}
+	}
+	return set, true
+}
+
+func (h *Handlers) RandomPort(c *gin.Context) {
+	min, err1 := strconv.Atoi(c.DefaultQuery("min", "49152"))
+	max, err2 := strconv.Atoi(c.DefaultQuery("max", "65535"))
+	if err1 != nil || err2 != nil || min < 1 || max > 65535 || min > max {
+		responder{c}.err(http.StatusBadRequest, "Port range must satisfy 1 <= min <= max <= 65535.")
+		return
+	}
+
+	exclude, ok := parsePortList(c.Query("exclude"))
+	if !ok {
+		responder{c}.err(http.StatusBadRequest, "Exclude must be a comma-separated list of ports or ranges.")
+		return
+	}
+
+	// Ports in a batch are distinct, so count is also bounded by the range.
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+	available := 0
+	for p := min; p <= max; p++ {
+		if !exclude[p] {
+			available++
+		}
+	}
+	if count > available {
+		responder{c}.err(http.StatusBadRequest, "Not enough available ports in the range.")
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		ports, err := rng.RandomPorts(h.r, h.health, min, max, exclude, count)
+		if err != nil {
+			return "", nil, http.StatusInternalServerError,
+				"Error fetching a random port."
+		}
+
+		lines := make([]string, len(ports))
+		for i, p := range ports {
+			lines[i] = strconv.Itoa(p)
+		}
+
+		payload := gin.H{"min": min, "max": max}
+		if batch {
+			payload["count"] = count
+			payload["results"] = ports
+		} else {
+			payload["port"] = ports[0]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+// otpSpecFromQuery reads the parameters shared by /otp/secret and
+// /otp/verify. It writes the error response itself.
+func otpSpecFromQuery(c *gin.Context) (rng.OTPSpec, bool) {
+	spec := rng.OTPSpec{
+		Type:      strings.ToLower(c.DefaultQuery("type", "totp")),
+		Issuer:    c.Query("issuer"),
+		Account:   c.DefaultQuery("account", "user"),
+		Algorithm: strings.ToUpper(c.DefaultQuery("algorithm", "SHA1")),
+	}
+
+	var err error
+	if spec.Digits, err = strconv.Atoi(c.DefaultQuery("digits", "6")); err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid digits.")
+		return spec, false
+	}
+	if spec.Period, err = strconv.Atoi(c.DefaultQuery("period", "30")); err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid period.")
+		return spec, false
+	}
+	if spec.Counter, err = strconv.ParseUint(c.DefaultQuery("counter", "0"), 10, 64); err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid counter.")
+		return spec, false
+	}
+	if err := spec.Validate(); err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return spec, false
+	}
+	return spec, true
+}
+
+func (h *Handlers) OTPSecret(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+
+	spec, ok := otpSpecFromQuery(c)
+	if !ok {
+		return
+	}
+	if strings.Contains(spec.Issuer, ":") || strings.Contains(spec.Account, ":") {
+		responder{c}.err(http.StatusBadRequest, "Issuer and account must not contain ':'.")
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("bytes", "20"))
+	if err != nil || size < 16 || size > 64 {
+		responder{c}.err(http.StatusBadRequest, "Bytes must be between 16 and 64.")
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		secret, err := rng.NewOTPSecret(h.r, h.health, size)
+		if err != nil {
+			return "", nil, http.StatusInternalServerError,
+				"Error fetching random bytes."
+		}
+		uri, err := rng.OTPURI(secret, spec)
+		if err != nil {
+			return "", nil, http.StatusBadRequest, err.Error()
+		}
+
+		payload := gin.H{
+			"type":      spec.Type,
+			"secret":    secret,
+			"uri":       uri,
+			"issuer":    spec.Issuer,
+			"account":   spec.Account,
+			"algorithm": spec.Algorithm,
+			"digits":    spec.Digits,
+		}
+		if spec.Type == "totp" {
+			payload["period"] = spec.Period
+		} else {
+			payload["counter"] = spec.Counter
+		}
+		return secret + "\n" + uri, payload, 0, ""
+	})
+}
+
+// OTPVerify takes the secret and code from a POST body,
+// {"secret": "...", "code": "..."}, so the secret stays out of URLs and
+// request logs. It needs no randomness, so it is served without the RNG
+// health check and answers with a fixed request_id, like /health.
+func (h *Handlers) OTPVerify(c *gin.Context) {
+	spec, ok := otpSpecFromQuery(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := c.GetQuery("secret"); ok {
+		responder{c}.err(http.StatusBadRequest, "Send the secret in a POST body, not the query string.")
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Request body too large or unreadable.")
+		return
+	}
+	var body struct {
+		Secret string `json:"secret"`
+		Code   string `json:"code"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		responder{c}.err(http.StatusBadRequest, `Body must be a JSON object such as {"secret": "...", "code": "..."}.`)
+		return
+	}
+
+	key, err := rng.DecodeOTPSecret(body.Secret)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Secret must be base32.")
+		return
+	}
+
+	if len(body.Code) != spec.Digits {
+		responder{c}.err(http.StatusBadRequest, "Code must have "+strconv.Itoa(spec.Digits)+" digits.")
+		return
+	}
+
+	window, err := strconv.Atoi(c.DefaultQuery("window", "1"))
+	if err != nil || window < 0 || window > 10 {
+		responder{c}.err(http.StatusBadRequest, "Window must be between 0 and 10.")
+		return
+	}
+
+	valid, offset, err := rng.VerifyOTP(key, body.Code, spec, time.Now(), window)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	payload := gin.H{"type": spec.Type, "valid": valid}
+	text := "invalid"
+	if valid {
+		payload["offset"] = offset
+		text = "valid (offset " + strconv.Itoa(offset) + ")"
+	}
+	responder{c}.ok(text, payload, "otp-verify")
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomOutcome(c *gin.Context) {
+	const maxOutcomes = 1000
+
+	names, percents, err := parseNamedValues(c.QueryArray("outcomes"), "")
+	if err != nil || len(names) == 0 || len(names) > maxOutcomes {
+		responder{c}.err(http.StatusBadRequest,
+			"Outcomes must be a comma-separated list of name:percent pairs (1 to 1000 outcomes).")
+		return
+	}
+
+	probs := make([]rng.Probability, len(names))
+	for i, p := range percents {
+		if probs[i], err = rng.ParsePercentProbability(p); err != nil {
+			responder{c}.err(http.StatusBadRequest, names[i]+": "+err.Error())
+			return
+		}
+	}
+
+	table, err := rng.NewOutcomeTable(probs)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]gin.H, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			index, roll, err := table.Roll(h.r, h.health)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random number."
+			}
+			results = append(results, gin.H{"outcome": names[index], "roll": roll})
+
+			sep := "\n"
+			if batch {
+				sep = ": "
+			}
+			lines = append(lines, fmt.Sprintf("Rolled %s from %s%s%s", roll, table.Den, sep, names[index]))
+		}
+
+		outcomes := make([]gin.H, len(names))
+		for i := range names {
+			outcomes[i] = gin.H{"name": names[i], "percent": percents[i], "weight": table.Weights[i]}
+		}
+
+		payload := gin.H{"outcomes": outcomes, "out_of": table.Den}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["outcome"] = results[0]["outcome"]
+			payload["roll"] = results[0]["roll"]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
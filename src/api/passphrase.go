@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomPassphrase(c *gin.Context) {
+	const maxWords = 64
+	const maxInjected = 16
+	const maxSeparator = 8
+
+	wordlistName := c.DefaultQuery("wordlist", "large")
+	words, ok := rng.Wordlist(wordlistName)
+	if !ok {
+		responder{c}.err(http.StatusBadRequest, "Wordlist must be large or short.")
+		return
+	}
+
+	numWords, err := strconv.Atoi(c.DefaultQuery("words", "6"))
+	if err != nil || numWords < 1 || numWords > maxWords {
+		responder{c}.err(http.StatusBadRequest, "Invalid word count.")
+		return
+	}
+
+	separator := c.DefaultQuery("separator", "-")
+	if separator == "" || len(separator) > maxSeparator {
+		responder{c}.err(http.StatusBadRequest, "Separator must be 1 to 8 characters.")
+		return
+	}
+
+	capitalize := c.DefaultQuery("capitalize", "none")
+	switch capitalize {
+	case "none", "first", "upper", "random":
+	default:
+		responder{c}.err(http.StatusBadRequest, "Capitalize must be none, first, upper or random.")
+		return
+	}
+
+	digits, err := strconv.Atoi(c.DefaultQuery("digits", "0"))
+	if err != nil || digits < 0 || digits > maxInjected {
+		responder{c}.err(http.StatusBadRequest, "Invalid digits count.")
+		return
+	}
+
+	symbols, err := strconv.Atoi(c.DefaultQuery("symbols", "0"))
+	if err != nil || symbols < 0 || symbols > maxInjected {
+		responder{c}.err(http.StatusBadRequest, "Invalid symbols count.")
+		return
+	}
+
+	spec := rng.PassphraseSpec{
+		Words:      words,
+		Count:      numWords,
+		Separator:  separator,
+		Capitalize: capitalize,
+		Digits:     digits,
+		Symbols:    symbols,
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]string, 0, count)
+		var entropy float64
+		for i := 0; i < count; i++ {
+			phrase, bits, err := rng.RandomPassphrase(h.r, h.health, spec)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random word."
+			}
+			results = append(results, phrase)
+			entropy = bits
+		}
+
+		payload := gin.H{
+			"wordlist":     wordlistName,
+			"words":        numWords,
+			"separator":    separator,
+			"capitalize":   capitalize,
+			"digits":       digits,
+			"symbols":      symbols,
+			"entropy_bits": entropy,
+		}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["passphrase"] = results[0]
+		}
+		return strings.Join(results, "\n"), payload, 0, ""
+	})
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomPattern(c *gin.Context) {
+	template := c.DefaultQuery("pattern", "Cvccvc99")
+
+	pattern, err := rng.ParsePattern(template)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			s, err := pattern.Generate(h.r, h.health)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random character."
+			}
+			results = append(results, s)
+		}
+
+		payload := gin.H{
+			"pattern":      template,
+			"entropy_bits": pattern.EntropyBits(),
+		}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["value"] = results[0]
+		}
+		return strings.Join(results, "\n"), payload, 0, ""
+	})
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+// PokerDeal deals one poker hand from a single shuffled deck and, with
+// evaluate=true, ranks every player's best hand and names the winners.
+func (h *Handlers) PokerDeal(c *gin.Context) {
+	game := c.DefaultQuery("game", "holdem")
+	g, ok := rng.LookupPokerGame(game)
+	if !ok {
+		responder{c}.err(http.StatusBadRequest, "Game must be holdem, omaha or draw.")
+		return
+	}
+
+	burn, err := strconv.ParseBool(c.DefaultQuery("burn", "true"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid burn flag.")
+		return
+	}
+
+	players, err := strconv.Atoi(c.DefaultQuery("players", "2"))
+	if err != nil || players < 2 || players > g.MaxPlayers(burn) {
+		responder{c}.err(http.StatusBadRequest,
+			fmt.Sprintf("Players must be between 2 and %d for %s.", g.MaxPlayers(burn), game))
+		return
+	}
+
+	evaluate, err := strconv.ParseBool(c.DefaultQuery("evaluate", "false"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid evaluate flag.")
+		return
+	}
+
+	format, ok := cardFormatFromQuery(c)
+	if !ok {
+		return
+	}
+	def, _ := rng.LookupDeckType("standard")
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		deal, err := rng.DealPoker(h.r, h.health, g, players, burn)
+		if err != nil {
+			return "", nil, http.StatusInternalServerError, "Error shuffling the deck."
+		}
+
+		payload := gin.H{"game": game}
+		// cards renders a set of cards as one text line and its JSON items.
+		cards := func(set []rng.Card) (string, any) {
+			text, items := formatCards(def, format, set, payload)
+			return strings.ReplaceAll(text, "\n", ", "), items
+		}
+
+		var lines []string
+		seats := make([]gin.H, players)
+		for p, hole := range deal.Hands {
+			text, items := cards(hole)
+			seats[p] = gin.H{"seat": p + 1, "hole": items}
+			lines = append(lines, fmt.Sprintf("Seat %d: %s", p+1, text))
+		}
+		payload["players"] = seats
+
+		if g.Board {
+			text, items := cards(deal.Board)
+			payload["board"] = items
+			lines = append(lines, "Board: "+text)
+			if burn {
+				_, payload["burned"] = cards(deal.Burned)
+			}
+		}
+
+		if evaluate {
+			hands, winners, err := g.Showdown(deal)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError, "Error evaluating hands."
+			}
+			for p, v := range hands {
+				text, items := cards(v.Cards)
+				seats[p]["hand"] = gin.H{"category": v.Name(), "rank": v.Category, "cards": items}
+				lines = append(lines, fmt.Sprintf("Seat %d: %s (%s)", p+1, v.Name(), text))
+			}
+			seatNums := make([]int, len(winners))
+			names := make([]string, len(winners))
+			for i, w := range winners {
+				seatNums[i], names[i] = w+1, strconv.Itoa(w+1)
+			}
+			payload["winners"] = seatNums
+			lines = append(lines, "Winners: "+strings.Join(names, ", "))
+		}
+
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
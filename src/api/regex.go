@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func (h *Handlers) RandomRegex(c *gin.Context) {
+	const maxPattern = 1024
+
+	pattern, ok := c.GetQuery("pattern")
+	if !ok || pattern == "" || len(pattern) > maxPattern {
+		responder{c}.err(http.StatusBadRequest, "Pattern is required (max 1024 bytes).")
+		return
+	}
+
+	maxRepeat, err := strconv.Atoi(c.DefaultQuery("max_repeat", "10"))
+	if err != nil || maxRepeat < 0 || maxRepeat > rng.MaxRegexRepeat {
+		responder{c}.err(http.StatusBadRequest,
+			fmt.Sprintf("Max repeat must be an integer between 0 and %d.", rng.MaxRegexRepeat))
+		return
+	}
+
+	re, err := rng.ParseRegex(pattern, maxRepeat)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			s, err := re.Generate(h.r, h.health)
+			if errors.Is(err, rng.ErrRegexTooLong) {
+				return "", nil, http.StatusBadRequest, err.Error()
+			}
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random character."
+			}
+			results = append(results, s)
+		}
+
+		payload := gin.H{
+			"pattern":    pattern,
+			"max_repeat": maxRepeat,
+		}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["value"] = results[0]
+		}
+		return strings.Join(results, "\n"), payload, 0, ""
+	})
+}
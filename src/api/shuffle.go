@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+const maxBodyBytes = 1 << 20
+
+// maxBatchValues bounds the values in one batch of whole lists, such as
+// count sequences or count draws of k picks.
+const maxBatchValues = 100_000
+
+// readItems collects a list of strings from either the request body (POST)
+// or comma-separated `items` query values (GET).
+//
+// Bodies may be a JSON array, a JSON object with an "items" array, or
+// newline-separated plain text.
+func readItems(c *gin.Context) ([]string, error) {
+	if c.Request.Method != http.MethodPost {
+		var items []string
+		for _, list := range c.QueryArray("items") {
+			for _, item := range strings.Split(list, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		return items, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+	if err != nil {
+		return nil, errors.New("request body too large or unreadable")
+	}
+
+	if strings.Contains(strings.ToLower(c.ContentType()), "json") {
+		var items []string
+		if err := json.Unmarshal(body, &items); err == nil {
+			return items, nil
+		}
+		var wrapped struct {
+			Items []string `json:"items"`
+		}
+		if err := json.Unmarshal(body, &wrapped); err != nil {
+			return nil, errors.New("body must be a JSON array of strings or {\"items\": [...]}")
+		}
+		return wrapped.Items, nil
+	}
+
+	var items []string
+	for _, line := range strings.Split(string(body), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items, nil
+}
+
+func (h *Handlers) RandomShuffle(c *gin.Context) {
+	const maxItems = 10_000
+
+	items, err := readItems(c)
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(items) == 0 || len(items) > maxItems {
+		responder{c}.err(http.StatusBadRequest, "Item count must be between 1 and 10000.")
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		shuffled := make([]string, len(items))
+		copy(shuffled, items)
+		if err := rng.Shuffle(h.r, h.health, shuffled); err != nil {
+			return "", nil, http.StatusInternalServerError,
+				"Error fetching a random index."
+		}
+
+		return strings.Join(shuffled, "\n"), gin.H{
+			"count":    len(shuffled),
+			"shuffled": shuffled,
+		}, 0, ""
+	})
+}
+
+func (h *Handlers) RandomSequence(c *gin.Context) {
+	const maxLength = 10_000
+
+	min, err := strconv.Atoi(c.DefaultQuery("min", "1"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid min value.")
+		return
+	}
+
+	max, err := strconv.Atoi(c.DefaultQuery("max", "10"))
+	if err != nil {
+		responder{c}.err(http.StatusBadRequest, "Invalid max value.")
+		return
+	}
+
+	if min < -1_000_000_000 || max > 1_000_000_000 {
+		responder{c}.err(http.StatusBadRequest,
+			"min and max must be between -1,000,000,000 and 1,000,000,000")
+		return
+	}
+	if min > max || max-min >= maxLength {
+		responder{c}.err(http.StatusBadRequest,
+			"Range must satisfy min <= max and contain at most 10000 values.")
+		return
+	}
+
+	count, batch, ok := h.batchCount(c)
+	if !ok {
+		return
+	}
+	if count*(max-min+1) > maxBatchValues {
+		responder{c}.err(http.StatusBadRequest, "The range size times count may not exceed 100000.")
+		return
+	}
+
+	h.handleRNG(c, func() (string, gin.H, int, string) {
+		results := make([][]int, 0, count)
+		lines := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			seq, err := rng.Sequence(h.r, h.health, min, max)
+			if err != nil {
+				return "", nil, http.StatusInternalServerError,
+					"Error fetching a random index."
+			}
+			results = append(results, seq)
+
+			values := make([]string, len(seq))
+			for j, v := range seq {
+				values[j] = strconv.Itoa(v)
+			}
+			sep := "\n"
+			if batch {
+				sep = ", "
+			}
+			lines = append(lines, strings.Join(values, sep))
+		}
+
+		payload := gin.H{"min": min, "max": max}
+		if batch {
+			payload["count"] = count
+			payload["results"] = results
+		} else {
+			payload["sequence"] = results[0]
+		}
+		return strings.Join(lines, "\n"), payload, 0, ""
+	})
+}
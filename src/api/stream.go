@@ -0,0 +1,219 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+const (
+	maxStreamRate  = 20 // events per second per subscriber
+	maxStreamBytes = 256
+)
+
+// MaxStreamsFromEnv returns how many /stream subscribers may be connected at
+// once. Configurable via MAX_STREAM_SUBSCRIBERS (default 16).
+func MaxStreamsFromEnv() int {
+	if n, err := strconv.Atoi(os.Getenv("MAX_STREAM_SUBSCRIBERS")); err == nil && n > 0 {
+		return n
+	}
+	return 16
+}
+
+// streamEvent is one value on a /stream feed.
+type streamEvent struct {
+	ID    string `json:"id"`
+	Seq   int    `json:"seq"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+	Roll  any    `json:"roll,omitempty"` // percent only
+}
+
+// streamGenerator builds the per-event generator from the query params. It
+// writes the error response itself.
+func (h *Handlers) streamGenerator(c *gin.Context) (kind string, gen func() (streamEvent, error), ok bool) {
+	kind = c.DefaultQuery("type", "number")
+	switch kind {
+	case "number":
+		min, err1 := strconv.Atoi(c.DefaultQuery("min", "1"))
+		max, err2 := strconv.Atoi(c.DefaultQuery("max", "100"))
+		if err1 != nil || err2 != nil || min > max {
+			responder{c}.err(http.StatusBadRequest, "Invalid min or max value.")
+			return "", nil, false
+		}
+		// Checked here so a bad range is a 400, not an error event.
+		if min < -1_000_000_000 || max > 1_000_000_000 {
+			responder{c}.err(http.StatusBadRequest,
+				"min and max must be between -1,000,000,000 and 1,000,000,000")
+			return "", nil, false
+		}
+		return kind, func() (streamEvent, error) {
+			n, err := rng.UniformInt32(h.r, h.health, min, max)
+			return streamEvent{Value: n}, err
+		}, true
+
+	case "bytes":
+		size, err := strconv.Atoi(c.DefaultQuery("size", "1"))
+		if err != nil || size < 1 || size > maxStreamBytes {
+			responder{c}.err(http.StatusBadRequest,
+				fmt.Sprintf("Size must be an integer between 1 and %d.", maxStreamBytes))
+			return "", nil, false
+		}
+		return kind, func() (streamEvent, error) {
+			buf := make([]byte, size)
+			if _, err := io.ReadFull(h.r, buf); err != nil {
+				if h.health != nil {
+					h.health.Set(false, "error fetching random bytes: "+err.Error())
+				}
+				return streamEvent{}, err
+			}
+			return streamEvent{Value: hex.EncodeToString(buf)}, nil
+		}, true
+
+	case "percent":
+		prob, err := rng.ParsePercentProbability(c.DefaultQuery("percent", "25"))
+		if err != nil {
+			responder{c}.err(http.StatusBadRequest, err.Error())
+			return "", nil, false
+		}
+		return kind, func() (streamEvent, error) {
+			roll, pass, err := prob.Roll(h.r, h.health)
+			return streamEvent{Value: pass, Roll: roll}, err
+		}, true
+	}
+
+	responder{c}.err(http.StatusBadRequest, "Type must be number, bytes or percent.")
+	return "", nil, false
+}
+
+// Stream serves a continuous feed of random values, as Server-Sent Events
+// or, when the request is a WebSocket upgrade, as JSON WebSocket messages.
+//
+// Backpressure: every event is generated only when its tick comes up and
+// the previous event has been written, and ticks are dropped rather than
+// queued, so a slow subscriber simply receives fewer events. Each event is a
+// small bounded read, so no subscriber holds the shared reader for long, and
+// the number of concurrent subscribers is capped.
+func (h *Handlers) Stream(c *gin.Context) {
+	kind, gen, ok := h.streamGenerator(c)
+	if !ok {
+		return
+	}
+
+	rate, err := strconv.ParseFloat(c.DefaultQuery("rate", "1"), 64)
+	if err != nil || !(rate > 0) || rate > maxStreamRate {
+		responder{c}.err(http.StatusBadRequest,
+			fmt.Sprintf("Rate must be greater than 0 and at most %d events per second.", maxStreamRate))
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 {
+		responder{c}.err(http.StatusBadRequest, "Invalid limit.")
+		return
+	}
+
+	if !h.rngOK(c) {
+		return
+	}
+
+	select {
+	case h.streams <- struct{}{}:
+		defer func() { <-h.streams }()
+	default:
+		responder{c}.err(http.StatusServiceUnavailable, "Too many stream subscribers; try again later.")
+		return
+	}
+
+	// next produces one event, or an error message for the client.
+	seq := 0
+	next := func() (streamEvent, string) {
+		ev, err := gen()
+		if err != nil {
+			h.log.Error(err)
+			return ev, "Error fetching a random value."
+		}
+		if ev.ID, err = h.uuidFromRNG(); err != nil {
+			return ev, "Error generating event id."
+		}
+		seq++
+		ev.Seq, ev.Type = seq, kind
+		return ev, ""
+	}
+	interval := time.Duration(float64(time.Second) / rate)
+
+	if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		websocket.Server{
+			// Origins are not restricted, matching the CORS policy.
+			Handshake: func(*websocket.Config, *http.Request) error { return nil },
+			Handler: func(ws *websocket.Conn) {
+				ctx, cancel := context.WithCancel(c.Request.Context())
+				defer cancel()
+
+				// Reading is the only way to notice a closed WebSocket.
+				go func() {
+					_, _ = io.Copy(io.Discard, ws)
+					cancel()
+				}()
+
+				runStream(ctx, interval, limit, next, func(ev streamEvent, errMsg string) error {
+					if errMsg != "" {
+						return websocket.JSON.Send(ws, gin.H{"error": errMsg})
+					}
+					return websocket.JSON.Send(ws, ev)
+				})
+			},
+		}.ServeHTTP(c.Writer, c.Request)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Status(http.StatusOK)
+	runStream(c.Request.Context(), interval, limit, next, func(ev streamEvent, errMsg string) error {
+		if errMsg != "" {
+			c.Render(-1, sse.Event{Event: "error", Data: errMsg})
+		} else {
+			c.Render(-1, sse.Event{Id: ev.ID, Event: kind, Data: ev})
+		}
+		c.Writer.Flush()
+		return c.Request.Context().Err()
+	})
+}
+
+// runStream sends an event right away and then on every tick until the
+// context ends, limit events have been sent (0 = no limit), sending fails or
+// the generator reports an error.
+func runStream(ctx context.Context, interval time.Duration, limit int,
+	next func() (streamEvent, string), send func(streamEvent, string) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for sent := 0; limit == 0 || sent < limit; sent++ {
+		ev, errMsg := next()
+		if err := send(ev, errMsg); err != nil || errMsg != "" {
+			return
+		}
+		if limit != 0 && sent+1 == limit {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
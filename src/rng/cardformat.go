@@ -0,0 +1,329 @@
+package rng
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Card output formats.
+const (
+	FormatName  = "name"  // "Ace of Spades", as named by the deck type
+	FormatCode  = "code"  // short code such as "AS" or "10H"
+	FormatGlyph = "glyph" // Unicode character such as "🂡"
+	FormatIndex = "index" // "rank:suit", 1-based positions in RankNames and SuitNames
+)
+
+// CardFormats lists the formats in the order they are documented.
+var CardFormats = []string{FormatName, FormatCode, FormatGlyph, FormatIndex}
+
+// reversedCodeSuffix marks a reversed card in code and index form.
+const reversedCodeSuffix = "r"
+
+func (t *DeckType) entries() []DeckEntry {
+	return append(append([]DeckEntry(nil), t.Extra...), t.Jokers...)
+}
+
+func (t *DeckType) hasCodes() bool {
+	if len(t.Values) > 0 && (len(t.ValueCodes) != len(t.Values) || len(t.SuitCodes) != len(t.Suits)) {
+		return false
+	}
+	for _, e := range t.entries() {
+		if e.Code == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// deckIndex holds a deck type's lookups. It is built once, on first use, so
+// formatting and parsing cost O(1) per card however large the type is.
+type deckIndex struct {
+	ranks, suits   []string
+	rankOf, suitOf map[string]int          // 1-based positions in ranks and suits
+	gridValue      map[string]int          // 0-based positions in Values
+	gridSuit       map[string]int          // 0-based positions in Suits
+	entry          map[[2]string]DeckEntry // first extra card or joker per value and suit
+	lookup         map[string]Card         // every rendering, lowercased, for parsing
+}
+
+func (t *DeckType) index() *deckIndex {
+	t.indexOnce.Do(func() {
+		ix := &deckIndex{
+			rankOf:    map[string]int{},
+			suitOf:    map[string]int{},
+			gridValue: map[string]int{},
+			gridSuit:  map[string]int{},
+			entry:     map[[2]string]DeckEntry{},
+			lookup:    map[string]Card{},
+		}
+		addRank := func(v string) {
+			if _, ok := ix.rankOf[v]; !ok {
+				ix.ranks = append(ix.ranks, v)
+				ix.rankOf[v] = len(ix.ranks)
+			}
+		}
+		addSuit := func(s string) {
+			if _, ok := ix.suitOf[s]; s != "" && !ok {
+				ix.suits = append(ix.suits, s)
+				ix.suitOf[s] = len(ix.suits)
+			}
+		}
+		for i, v := range t.Values {
+			addRank(v)
+			if _, ok := ix.gridValue[v]; !ok {
+				ix.gridValue[v] = i
+			}
+		}
+		for i, s := range t.Suits {
+			addSuit(s)
+			if _, ok := ix.gridSuit[s]; !ok {
+				ix.gridSuit[s] = i
+			}
+		}
+		for _, e := range t.entries() {
+			addRank(e.Value)
+			addSuit(e.Suit)
+			if _, ok := ix.entry[[2]string{e.Value, e.Suit}]; !ok {
+				ix.entry[[2]string{e.Value, e.Suit}] = e
+			}
+		}
+
+		// Later formats win on collisions, so names take precedence.
+		var formats []string
+		for _, format := range []string{FormatIndex, FormatGlyph, FormatCode, FormatName} {
+			if t.SupportsFormat(format) {
+				formats = append(formats, format)
+			}
+		}
+		for _, c := range t.Build(1, true) {
+			for _, format := range formats {
+				ix.lookup[strings.ToLower(t.formatCard(ix, c, format))] = c
+			}
+		}
+		t.idx = ix
+	})
+	return t.idx
+}
+
+// validateCodes checks code lengths and, when every card has a code, that no
+// two different cards share one. It builds the deck, so the size must have
+// been checked first.
+func (t *DeckType) validateCodes() error {
+	if len(t.ValueCodes) > 0 && len(t.ValueCodes) != len(t.Values) {
+		return errors.New("value_codes must have one code per value")
+	}
+	if len(t.SuitCodes) > 0 && len(t.SuitCodes) != len(t.Suits) {
+		return errors.New("suit_codes must have one code per suit")
+	}
+	codes := append(append([]string(nil), t.ValueCodes...), t.SuitCodes...)
+	for _, e := range t.entries() {
+		codes = append(codes, e.Code)
+	}
+	for _, c := range codes {
+		if len(c) > 16 || strings.ContainsAny(c, " \t\n") {
+			return errors.New("codes must be at most 16 characters without spaces")
+		}
+	}
+
+	if !t.hasCodes() {
+		return nil
+	}
+	ix := t.index()
+	seen := map[string]Card{}
+	for _, c := range t.Build(1, true) {
+		code := t.code(ix, c)
+		if prev, ok := seen[strings.ToLower(code)]; ok && prev != c {
+			return fmt.Errorf("code %q is used by more than one card", code)
+		}
+		seen[strings.ToLower(code)] = c
+	}
+	return nil
+}
+
+// code returns a card's short code without orientation, or "" if it has none.
+func (t *DeckType) code(ix *deckIndex, c Card) string {
+	if e, ok := ix.entry[[2]string{c.Value, c.Suit}]; ok {
+		return e.Code
+	}
+	vi, okV := ix.gridValue[c.Value]
+	si, okS := ix.gridSuit[c.Suit]
+	if !okV || !okS || vi >= len(t.ValueCodes) || si >= len(t.SuitCodes) {
+		return ""
+	}
+	tmpl := t.CodeTemplate
+	if tmpl == "" {
+		tmpl = "{value}{suit}"
+	}
+	return strings.NewReplacer("{value}", t.ValueCodes[vi], "{suit}", t.SuitCodes[si]).Replace(tmpl)
+}
+
+// RankNames lists the values in index order: the grid values followed by
+// any other values of extra cards and jokers.
+func (t *DeckType) RankNames() []string {
+	return slices.Clone(t.index().ranks)
+}
+
+// SuitNames lists the suits in index order, like RankNames.
+func (t *DeckType) SuitNames() []string {
+	return slices.Clone(t.index().suits)
+}
+
+// Indices returns the 1-based positions of a card's value and suit in
+// RankNames and SuitNames; suit is 0 for cards without one.
+func (t *DeckType) Indices(c Card) (rank, suit int) {
+	ix := t.index()
+	return ix.rankOf[c.Value], ix.suitOf[c.Suit]
+}
+
+// SupportsFormat reports whether every card of the type can be rendered in
+// format.
+func (t *DeckType) SupportsFormat(format string) bool {
+	switch format {
+	case FormatName, FormatIndex:
+		return true
+	case FormatCode:
+		return t.hasCodes()
+	case FormatGlyph:
+		return t.glyph != nil
+	}
+	return false
+}
+
+// FormatCard renders a card in a format the type supports.
+func (t *DeckType) FormatCard(c Card, format string) string {
+	return t.formatCard(t.index(), c, format)
+}
+
+func (t *DeckType) formatCard(ix *deckIndex, c Card, format string) string {
+	var s string
+	switch format {
+	case FormatCode:
+		s = t.code(ix, c)
+	case FormatGlyph:
+		s, _ = t.glyph(c)
+		return s
+	case FormatIndex:
+		s = strconv.Itoa(ix.rankOf[c.Value]) + ":" + strconv.Itoa(ix.suitOf[c.Suit])
+	default:
+		return t.CardName(c)
+	}
+	if c.Reversed {
+		s += reversedCodeSuffix
+	}
+	return s
+}
+
+// ParseCards parses cards given in any supported format, ignoring case.
+// Reversed cards of reversible types may carry the " (reversed)" or "r"
+// suffix.
+func (t *DeckType) ParseCards(list []string) ([]Card, error) {
+	lookup := t.index().lookup
+
+	cards := make([]Card, len(list))
+	for i, s := range list {
+		key := strings.ToLower(strings.TrimSpace(s))
+		c, ok := lookup[key]
+		if !ok && t.Reversible {
+			for _, suffix := range []string{" (reversed)", reversedCodeSuffix} {
+				if base, found := strings.CutSuffix(key, suffix); found {
+					if c, ok = lookup[base]; ok {
+						c.Reversed = true
+						break
+					}
+				}
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown card %q", s)
+		}
+		cards[i] = c
+	}
+	return cards, nil
+}
+
+// DecodeCards parses a JSON array whose items are card objects
+// ({"value", "suit"}) or strings in any format ParseCards accepts.
+func (t *DeckType) DecodeCards(data []byte) ([]Card, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil || len(items) == 0 {
+		return nil, errors.New(`cards must be a non-empty JSON array of card objects or strings such as "AS"`)
+	}
+
+	cards := make([]Card, len(items))
+	for i, item := range items {
+		var s string
+		if err := json.Unmarshal(item, &s); err == nil {
+			parsed, err := t.ParseCards([]string{s})
+			if err != nil {
+				return nil, err
+			}
+			cards[i] = parsed[0]
+			continue
+		}
+		if err := json.Unmarshal(item, &cards[i]); err != nil || cards[i].Value == "" {
+			return nil, fmt.Errorf("invalid card %s", item)
+		}
+	}
+	return cards, nil
+}
+
+// frenchGlyph maps French-suited cards to the Unicode Playing Cards block.
+func frenchGlyph(c Card) (string, bool) {
+	if c.Value == "Joker" {
+		switch c.Suit {
+		case "Red":
+			return "\U0001F0BF", true
+		case "Black":
+			return "\U0001F0CF", true
+		}
+		return "", false
+	}
+
+	base := map[string]rune{"Spades": 0x1F0A0, "Hearts": 0x1F0B0, "Diamonds": 0x1F0C0, "Clubs": 0x1F0D0}[c.Suit]
+	// The block has a knight between jack and queen.
+	rank := map[string]rune{
+		"Ace": 1, "Two": 2, "Three": 3, "Four": 4, "Five": 5, "Six": 6, "Seven": 7,
+		"Eight": 8, "Nine": 9, "Ten": 10, "Jack": 11, "Queen": 13, "King": 14,
+	}[c.Value]
+	if base == 0 || rank == 0 {
+		return "", false
+	}
+	return string(base + rank), true
+}
+
+// mahjongGlyph maps tiles to the Unicode Mahjong Tiles block.
+func mahjongGlyph(c Card) (string, bool) {
+	if base, ok := map[string]rune{"Characters": 0x1F007, "Bamboo": 0x1F010, "Dots": 0x1F019}[c.Suit]; ok {
+		n, err := strconv.Atoi(c.Value)
+		if err != nil || n < 1 || n > 9 {
+			return "", false
+		}
+		return string(base + rune(n-1)), true
+	}
+
+	r, ok := map[[2]string]rune{
+		{"Wind", "East"}: 0x1F000, {"Wind", "South"}: 0x1F001, {"Wind", "West"}: 0x1F002, {"Wind", "North"}: 0x1F003,
+		{"Dragon", "Red"}: 0x1F004, {"Dragon", "Green"}: 0x1F005, {"Dragon", "White"}: 0x1F006,
+		{"Flower", "Plum"}: 0x1F022, {"Flower", "Orchid"}: 0x1F023, {"Flower", "Bamboo"}: 0x1F024, {"Flower", "Chrysanthemum"}: 0x1F025,
+		{"Season", "Spring"}: 0x1F026, {"Season", "Summer"}: 0x1F027, {"Season", "Autumn"}: 0x1F028, {"Season", "Winter"}: 0x1F029,
+	}[[2]string{c.Suit, c.Value}]
+	if !ok {
+		return "", false
+	}
+	return string(r), true
+}
+
+// dominoGlyph maps tiles to the horizontal tiles of the Unicode Domino Tiles
+// block, lower end on the left.
+func dominoGlyph(c Card) (string, bool) {
+	a, err1 := strconv.Atoi(c.Suit)
+	b, err2 := strconv.Atoi(c.Value)
+	if err1 != nil || err2 != nil || a < 0 || b < 0 || a > 6 || b > 6 {
+		return "", false
+	}
+	return string(rune(0x1F031 + 7*a + b)), true
+}
@@ -1,34 +1,30 @@
 package rng
 
+import "io"
+
 type Card struct {
-	Value string `json:"value"`
-	Suit  string `json:"suit"`
+	Value    string `json:"value"`
+	Suit     string `json:"suit"`
+	Reversed bool   `json:"reversed,omitempty"`
 }
 
+// AddDeck returns numDecks standard 52-card decks, with two jokers each when
+// jokers is set.
 func AddDeck(numDecks int, jokers bool) []Card {
-	perDeck := 52
-	if jokers {
-		perDeck += 2
-	}
-	deck := make([]Card, 0, numDecks*perDeck)
-
-	suits := []string{"Hearts", "Diamonds", "Clubs", "Spades"}
-	values := []string{"Ace", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten", "Jack", "Queen", "King"}
-
-	for d := 0; d < numDecks; d++ {
-		for _, suit := range suits {
-			for _, v := range values {
-				deck = append(deck, Card{Value: v, Suit: suit})
-			}
-		}
-		if jokers {
-			deck = append(deck, Card{Value: "Joker", Suit: "Red"})
-			deck = append(deck, Card{Value: "Joker", Suit: "Black"})
-		}
-	}
-	return deck
+	return deckTypes["standard"].Build(numDecks, jokers)
 }
 
-func RemoveCard(deck []Card, index int) []Card {
-	return append(deck[:index], deck[index+1:]...)
+// DrawCards draws k cards without replacement from a shoe of numDecks copies
+// of deck. The shoe is never built: position p of the shoe is
+// deck[p % len(deck)], and PartialShuffle picks k distinct positions.
+func DrawCards(r io.Reader, h *Health, deck []Card, numDecks int, k int) ([]Card, error) {
+	positions, err := PartialShuffle(r, h, len(deck)*numDecks, k)
+	if err != nil {
+		return nil, err
+	}
+	cards := make([]Card, k)
+	for i, p := range positions {
+		cards[i] = deck[p%len(deck)]
+	}
+	return cards, nil
 }
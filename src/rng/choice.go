@@ -0,0 +1,140 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxWeightTotal keeps the summed weights within UniformInt32's range.
+const maxWeightTotal = 1_000_000_000
+
+// ParseWeights parses integer or exact decimal weights ("3", "2.5", "0.125")
+// and scales them to a common integer denominator, so that the returned
+// weights are proportional to the inputs with no rounding.
+//
+// Rejects negatives, an all-zero total, and totals that don't fit the
+// uniform sampler once scaled.
+func ParseWeights(weightStrs []string) ([]int, error) {
+	if len(weightStrs) == 0 {
+		return nil, errors.New("at least one weight is required")
+	}
+
+	intParts := make([]string, len(weightStrs))
+	fracParts := make([]string, len(weightStrs))
+	decimals := 0
+
+	for i, raw := range weightStrs {
+		s := strings.TrimPrefix(strings.TrimSpace(raw), "+")
+		if s == "" {
+			return nil, errors.New("weight is empty")
+		}
+		if strings.HasPrefix(s, "-") {
+			return nil, errors.New("weight must not be negative")
+		}
+		if strings.Count(s, ".") > 1 {
+			return nil, errors.New("invalid weight format")
+		}
+
+		intPart, fracPart, _ := strings.Cut(s, ".")
+		if intPart == "" {
+			intPart = "0"
+		}
+		for _, ch := range intPart + fracPart {
+			if ch < '0' || ch > '9' {
+				return nil, errors.New("invalid weight format")
+			}
+		}
+
+		fracPart = strings.TrimRight(fracPart, "0")
+		if len(fracPart) > 9 {
+			return nil, errors.New("too many decimal places; max is 9")
+		}
+		if len(fracPart) > decimals {
+			decimals = len(fracPart)
+		}
+		intParts[i] = intPart
+		fracParts[i] = fracPart
+	}
+
+	weights := make([]int, len(weightStrs))
+	total := 0
+	for i := range weightStrs {
+		// Pad every fraction to the common number of decimals.
+		digits := intParts[i] + fracParts[i] + strings.Repeat("0", decimals-len(fracParts[i]))
+		digits = strings.TrimLeft(digits, "0")
+		if digits == "" {
+			digits = "0"
+		}
+
+		w, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil || w > maxWeightTotal {
+			return nil, errors.New("weights too large or too precise")
+		}
+		weights[i] = int(w)
+		total += int(w)
+		if total > maxWeightTotal {
+			return nil, errors.New("weights too large or too precise")
+		}
+	}
+
+	if total == 0 {
+		return nil, errors.New("at least one weight must be positive")
+	}
+	return weights, nil
+}
+
+// WeightedChoice picks k indices into weights, each with probability
+// proportional to its weight. Without replacement, a picked item's weight is
+// removed before the next pick (successive sampling).
+//
+// Each pick rolls a single integer in [0, total) with UniformInt32 and walks
+// the cumulative weights, so the result is exact (no floats involved).
+func WeightedChoice(r io.Reader, h *Health, weights []int, k int, replace bool) ([]int, error) {
+	if k < 1 {
+		return nil, errors.New("k must be at least 1")
+	}
+
+	remaining := make([]int, len(weights))
+	copy(remaining, weights)
+
+	total, positive := 0, 0
+	for _, w := range remaining {
+		if w < 0 {
+			return nil, errors.New("weight must not be negative")
+		}
+		total += w
+		if w > 0 {
+			positive++
+		}
+	}
+	if total == 0 || total > maxWeightTotal {
+		return nil, errors.New("total weight must be between 1 and 1,000,000,000")
+	}
+	if !replace && k > positive {
+		return nil, errors.New("k exceeds the number of items with a positive weight")
+	}
+
+	picked := make([]int, 0, k)
+	for i := 0; i < k; i++ {
+		roll, err := UniformInt32(r, h, 0, total-1)
+		if err != nil {
+			return nil, err
+		}
+
+		acc := 0
+		for idx, w := range remaining {
+			acc += w
+			if int(roll) < acc {
+				picked = append(picked, idx)
+				if !replace {
+					total -= w
+					remaining[idx] = 0
+				}
+				break
+			}
+		}
+	}
+	return picked, nil
+}
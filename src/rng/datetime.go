@@ -0,0 +1,268 @@
+package rng
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	// Embed the zoneinfo database so time zones work in the scratch image.
+	_ "time/tzdata"
+)
+
+// maxFilteredDays bounds the calendar days a DateTimeSampler enumerates when
+// weekday/business-hours filters are active (about a century).
+const maxFilteredDays = 36_600
+
+// DateTimeSpec describes the instants RandomDateTime may return.
+type DateTimeSpec struct {
+	Start, End    time.Time
+	Granularity   string // "day", "minute", "second" or "millisecond"
+	Location      *time.Location
+	Weekdays      bool // Monday to Friday only
+	BusinessHours bool // 09:00 to 17:00 local time only
+}
+
+// ErrNoMatchingInstant is returned when no instant in the range passes the
+// filters.
+var ErrNoMatchingInstant = errors.New("no instant in range matches the filters")
+
+// bounds returns the range of granularity units to sample from and the
+// instant each unit stands for.
+func (spec DateTimeSpec) bounds() (lo, hi int64, at func(int64) time.Time, err error) {
+	loc := spec.location()
+	if spec.End.Before(spec.Start) {
+		return 0, 0, nil, errors.New("start must not be after end")
+	}
+	if spec.Granularity == "day" && spec.BusinessHours {
+		return 0, 0, nil, errors.New("business hours filter needs a granularity finer than a day")
+	}
+
+	switch spec.Granularity {
+	case "day":
+		// Count local calendar days via their UTC-midnight twins (immune to DST).
+		first := localMidnight(spec.Start.In(loc))
+		if first.Before(spec.Start) {
+			first = first.AddDate(0, 0, 1)
+		}
+		last := localMidnight(spec.End.In(loc))
+		lo, hi = 0, civilDay(last)-civilDay(first)
+		at = func(k int64) time.Time {
+			return time.Date(first.Year(), first.Month(), first.Day()+int(k), 0, 0, 0, 0, loc)
+		}
+	case "millisecond":
+		lo, hi = ceilUnits(spec.Start, time.Millisecond), floorUnits(spec.End, time.Millisecond)
+		at = func(k int64) time.Time { return time.UnixMilli(k).In(loc) }
+	case "second", "minute":
+		unit := spec.unit()
+		lo, hi = ceilUnits(spec.Start, unit), floorUnits(spec.End, unit)
+		at = func(k int64) time.Time { return time.Unix(k*int64(unit/time.Second), 0).In(loc) }
+	default:
+		return 0, 0, nil, errors.New("granularity must be day, minute, second or millisecond")
+	}
+
+	if lo > hi {
+		return 0, 0, nil, errors.New("range contains no instant at this granularity")
+	}
+	return lo, hi, at, nil
+}
+
+func (spec DateTimeSpec) location() *time.Location {
+	if spec.Location == nil {
+		return time.UTC
+	}
+	return spec.Location
+}
+
+// unit is the length of one granularity step below a day.
+func (spec DateTimeSpec) unit() time.Duration {
+	switch spec.Granularity {
+	case "millisecond":
+		return time.Millisecond
+	case "minute":
+		return time.Minute
+	}
+	return time.Second
+}
+
+// DateTimeSampler draws instants for a DateTimeSpec. The allowed instants are
+// laid out once as runs of granularity units, so each draw is a single
+// uniform index over their total length.
+type DateTimeSampler struct {
+	at    func(int64) time.Time
+	runs  []unitRun
+	total int64 // units across all runs
+}
+
+// unitRun is an inclusive range of granularity units, with the number of
+// allowed units in the runs before it.
+type unitRun struct {
+	lo, hi, before int64
+}
+
+// NewDateTimeSampler checks the spec and enumerates its allowed instants, so
+// callers can reject it before reading any entropy. It returns
+// ErrNoMatchingInstant if the filters leave nothing.
+func NewDateTimeSampler(spec DateTimeSpec) (*DateTimeSampler, error) {
+	lo, hi, at, err := spec.bounds()
+	if err != nil {
+		return nil, err
+	}
+	s := &DateTimeSampler{at: at}
+	if !spec.Weekdays && !spec.BusinessHours {
+		s.add(lo, hi)
+		return s, nil
+	}
+
+	loc := spec.location()
+	first := localMidnight(spec.Start.In(loc))
+	days := civilDay(spec.End.In(loc)) - civilDay(first) + 1
+	if days > maxFilteredDays {
+		return nil, fmt.Errorf("filtered ranges may span at most %d days", maxFilteredDays)
+	}
+
+	for d := 0; d < int(days); d++ {
+		day := time.Date(first.Year(), first.Month(), first.Day()+d, 0, 0, 0, 0, loc)
+		if spec.Weekdays && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			continue
+		}
+		if spec.Granularity == "day" {
+			k := civilDay(day) - civilDay(at(0))
+			s.add(max(k, lo), min(k, hi))
+			continue
+		}
+
+		// The allowed instants of the day are [from, to).
+		from, to := day, time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+		if spec.BusinessHours {
+			from = time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, loc)
+			to = time.Date(day.Year(), day.Month(), day.Day(), 17, 0, 0, 0, loc)
+		}
+		unit := spec.unit()
+		s.add(max(ceilUnits(from, unit), lo), min(ceilUnits(to, unit)-1, hi))
+	}
+
+	if s.total == 0 {
+		return nil, ErrNoMatchingInstant
+	}
+	return s, nil
+}
+
+// add appends the units [lo, hi], merging them into the previous run when
+// they continue it. Empty ranges are ignored.
+func (s *DateTimeSampler) add(lo, hi int64) {
+	if lo > hi {
+		return
+	}
+	if n := len(s.runs); n > 0 && s.runs[n-1].hi+1 == lo {
+		s.runs[n-1].hi = hi
+	} else {
+		s.runs = append(s.runs, unitRun{lo: lo, hi: hi, before: s.total})
+	}
+	s.total += hi - lo + 1
+}
+
+// Sample returns a uniformly random allowed instant.
+func (s *DateTimeSampler) Sample(r io.Reader, h *Health) (time.Time, error) {
+	k, err := UniformInt64(r, h, 0, s.total-1)
+	if err != nil {
+		return time.Time{}, err
+	}
+	i := sort.Search(len(s.runs), func(i int) bool { return s.runs[i].before > k }) - 1
+	run := s.runs[i]
+	return s.at(run.lo + k - run.before), nil
+}
+
+// RandomDateTime returns a uniformly random instant in [Start, End] that is a
+// whole multiple of the granularity (days are local midnights in Location)
+// and passes the filters. Callers drawing many instants should keep a
+// NewDateTimeSampler.
+func RandomDateTime(r io.Reader, h *Health, spec DateTimeSpec) (time.Time, error) {
+	s, err := NewDateTimeSampler(spec)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.Sample(r, h)
+}
+
+func localMidnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// civilDay numbers the calendar date of t (in its own location) as days since 1970-01-01.
+func civilDay(t time.Time) int64 {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+// floorUnits and ceilUnits convert t to a whole number of units since the
+// Unix epoch, rounding down or up. Units are milliseconds or whole seconds.
+func floorUnits(t time.Time, unit time.Duration) int64 {
+	if unit == time.Millisecond {
+		return t.UnixMilli()
+	}
+	return floorDiv(t.Unix(), int64(unit/time.Second))
+}
+
+func ceilUnits(t time.Time, unit time.Duration) int64 {
+	k := floorUnits(t, unit)
+	var back time.Time
+	if unit == time.Millisecond {
+		back = time.UnixMilli(k)
+	} else {
+		back = time.Unix(k*int64(unit/time.Second), 0)
+	}
+	if back.Before(t) {
+		k++
+	}
+	return k
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// ValidateDuration reports whether [min, max] holds a whole multiple of unit,
+// so callers can reject the range before reading any entropy.
+func ValidateDuration(min, max, unit time.Duration) error {
+	_, _, err := durationBounds(min, max, unit)
+	return err
+}
+
+func durationBounds(min, max, unit time.Duration) (lo, hi int64, err error) {
+	if unit <= 0 {
+		return 0, 0, errors.New("unit must be positive")
+	}
+	if min > max {
+		return 0, 0, errors.New("min must not be greater than max")
+	}
+
+	lo = floorDiv(int64(min), int64(unit))
+	if time.Duration(lo)*unit < min {
+		lo++
+	}
+	hi = floorDiv(int64(max), int64(unit))
+	if lo > hi {
+		return 0, 0, errors.New("range contains no duration at this granularity")
+	}
+	return lo, hi, nil
+}
+
+// RandomDuration returns a uniformly random duration in [min, max] that is a
+// whole multiple of unit.
+func RandomDuration(r io.Reader, h *Health, min, max, unit time.Duration) (time.Duration, error) {
+	lo, hi, err := durationBounds(min, max, unit)
+	if err != nil {
+		return 0, err
+	}
+
+	k, err := UniformInt64(r, h, lo, hi)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(k) * unit, nil
+}
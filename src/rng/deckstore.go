@@ -0,0 +1,276 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Deck store errors. The API maps them to 404/400/503 responses.
+var (
+	ErrDeckNotFound   = errors.New("deck not found")
+	ErrNotEnoughCards = errors.New("not enough cards left in the deck")
+	ErrCardNotDealt   = errors.New("card was not dealt from this deck")
+	ErrTooManyDecks   = errors.New("too many open decks")
+)
+
+// DeckConfig records how a stored deck was built. Reversals gives every card
+// a random orientation whenever the deck is shuffled.
+type DeckConfig struct {
+	Type       string    `json:"type"`
+	Decks      int       `json:"decks"`
+	Jokers     bool      `json:"jokers"`
+	Reversals  bool      `json:"reversals,omitempty"`
+	Definition *DeckType `json:"-"`
+}
+
+// DeckState is a snapshot of a stored deck.
+type DeckState struct {
+	ID        string     `json:"id"`
+	Config    DeckConfig `json:"config"`
+	Remaining int        `json:"remaining"`
+	Dealt     int        `json:"dealt"`
+	Discarded int        `json:"discarded"`
+	ExpiresAt time.Time  `json:"expires_at"`
+}
+
+// storedDeck is a shuffled shoe. Cards move from stack (top = last element)
+// to dealt (draw) or discards (burn); returned cards move from dealt to
+// discards, and a reshuffle puts the discards back into the stack.
+type storedDeck struct {
+	mu       sync.Mutex
+	id       string
+	config   DeckConfig
+	stack    []Card
+	dealt    []Card
+	discards []Card
+	expires  atomic.Int64 // Unix nanoseconds; read without mu by the sweep
+}
+
+func (d *storedDeck) state() DeckState {
+	return DeckState{
+		ID:        d.id,
+		Config:    d.config,
+		Remaining: len(d.stack),
+		Dealt:     len(d.dealt),
+		Discarded: len(d.discards),
+		ExpiresAt: time.Unix(0, d.expires.Load()).UTC(),
+	}
+}
+
+// top returns the next n cards in draw order without removing them.
+func (d *storedDeck) top(n int) ([]Card, error) {
+	if n < 0 || n > len(d.stack) {
+		return nil, ErrNotEnoughCards
+	}
+	out := make([]Card, n)
+	for i := range out {
+		out[i] = d.stack[len(d.stack)-1-i]
+	}
+	return out, nil
+}
+
+// DeckStore keeps shuffled decks between requests. Decks expire after ttl
+// without access; expired decks are swept lazily when new decks are created.
+type DeckStore struct {
+	mu       sync.Mutex
+	decks    map[string]*storedDeck
+	ttl      time.Duration
+	maxDecks int
+	reserved int // slots claimed by Create calls still shuffling
+}
+
+// NewDeckStore returns an empty store.
+func NewDeckStore(ttl time.Duration, maxDecks int) *DeckStore {
+	return &DeckStore{decks: map[string]*storedDeck{}, ttl: ttl, maxDecks: maxDecks}
+}
+
+// shuffleDeck shuffles cards in place and, with reversals, orients them.
+func shuffleDeck(r io.Reader, h *Health, config DeckConfig, cards []Card) error {
+	if err := Shuffle(r, h, cards); err != nil {
+		return err
+	}
+	if config.Reversals {
+		return Orient(r, h, cards)
+	}
+	return nil
+}
+
+// Create builds and shuffles a new stored deck from config.Definition. The
+// slot is reserved before any entropy is read, so a full store fails fast
+// with ErrTooManyDecks. The deck ID is a UUID drawn from r after the shuffle.
+func (s *DeckStore) Create(r io.Reader, h *Health, config DeckConfig) (DeckState, error) {
+	if err := s.reserve(); err != nil {
+		return DeckState{}, err
+	}
+	d, err := newStoredDeck(r, h, config)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reserved--
+	if err != nil {
+		return DeckState{}, err
+	}
+	d.expires.Store(time.Now().UnixNano() + int64(s.ttl))
+	s.decks[d.id] = d
+	return d.state(), nil
+}
+
+// newStoredDeck builds and shuffles a deck; it runs without the store lock.
+func newStoredDeck(r io.Reader, h *Health, config DeckConfig) (*storedDeck, error) {
+	stack := config.Definition.Build(config.Decks, config.Jokers)
+	if err := shuffleDeck(r, h, config, stack); err != nil {
+		return nil, err
+	}
+	id, err := NewUUIDv4FromRNG(r)
+	if err != nil {
+		return nil, err
+	}
+	return &storedDeck{id: id, config: config, stack: stack}, nil
+}
+
+// reserve sweeps expired decks and claims room for one more.
+func (s *DeckStore) reserve() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	for key, d := range s.decks {
+		if now > d.expires.Load() {
+			delete(s.decks, key)
+		}
+	}
+	if len(s.decks)+s.reserved >= s.maxDecks {
+		return ErrTooManyDecks
+	}
+	s.reserved++
+	return nil
+}
+
+// with runs fn on a live deck while holding its lock, and extends its expiry.
+func (s *DeckStore) with(id string, fn func(d *storedDeck) error) (DeckState, error) {
+	now := time.Now().UnixNano()
+
+	s.mu.Lock()
+	d, ok := s.decks[id]
+	if ok && now > d.expires.Load() {
+		delete(s.decks, id)
+		ok = false
+	}
+	s.mu.Unlock()
+	if !ok {
+		return DeckState{}, ErrDeckNotFound
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := fn(d); err != nil {
+		return DeckState{}, err
+	}
+	d.expires.Store(now + int64(s.ttl))
+	return d.state(), nil
+}
+
+// State returns a snapshot of the deck.
+func (s *DeckStore) State(id string) (DeckState, error) {
+	return s.with(id, func(*storedDeck) error { return nil })
+}
+
+// Draw deals n cards from the top of the deck.
+func (s *DeckStore) Draw(id string, n int) (cards []Card, state DeckState, err error) {
+	state, err = s.with(id, func(d *storedDeck) error {
+		if cards, err = d.top(n); err != nil {
+			return err
+		}
+		d.stack = d.stack[:len(d.stack)-n]
+		d.dealt = append(d.dealt, cards...)
+		return nil
+	})
+	return cards, state, err
+}
+
+// Peek returns the next n cards without dealing them.
+func (s *DeckStore) Peek(id string, n int) (cards []Card, state DeckState, err error) {
+	state, err = s.with(id, func(d *storedDeck) error {
+		cards, err = d.top(n)
+		return err
+	})
+	return cards, state, err
+}
+
+// Burn moves n cards from the top of the deck straight to the discards.
+func (s *DeckStore) Burn(id string, n int) (cards []Card, state DeckState, err error) {
+	state, err = s.with(id, func(d *storedDeck) error {
+		if cards, err = d.top(n); err != nil {
+			return err
+		}
+		d.stack = d.stack[:len(d.stack)-n]
+		d.discards = append(d.discards, cards...)
+		return nil
+	})
+	return cards, state, err
+}
+
+// Return moves dealt cards to the discards: all of them when all is set,
+// otherwise exactly the given cards, which must all have been dealt.
+// Orientation is ignored when matching.
+func (s *DeckStore) Return(id string, cards []Card, all bool) (DeckState, error) {
+	return s.with(id, func(d *storedDeck) error {
+		if all {
+			d.discards = append(d.discards, d.dealt...)
+			d.dealt = nil
+			return nil
+		}
+
+		// Validate everything first so a bad card changes nothing.
+		dealt := append([]Card(nil), d.dealt...)
+		returned := make([]Card, 0, len(cards))
+		for _, c := range cards {
+			i := indexOfCard(dealt, c)
+			if i < 0 {
+				return ErrCardNotDealt
+			}
+			returned = append(returned, dealt[i])
+			dealt = append(dealt[:i], dealt[i+1:]...)
+		}
+		d.dealt = dealt
+		d.discards = append(d.discards, returned...)
+		return nil
+	})
+}
+
+func indexOfCard(cards []Card, c Card) int {
+	for i, x := range cards {
+		if x.Value == c.Value && x.Suit == c.Suit {
+			return i
+		}
+	}
+	return -1
+}
+
+// Shuffle puts the discards back into the deck and shuffles the whole stack.
+// Dealt cards stay out until they are returned.
+func (s *DeckStore) Shuffle(r io.Reader, h *Health, id string) (DeckState, error) {
+	return s.with(id, func(d *storedDeck) error {
+		stack := append(append([]Card(nil), d.stack...), d.discards...)
+		if err := shuffleDeck(r, h, d.config, stack); err != nil {
+			return err
+		}
+		d.stack, d.discards = stack, nil
+		return nil
+	})
+}
+
+// Delete removes a deck.
+func (s *DeckStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.decks[id]; !ok {
+		return ErrDeckNotFound
+	}
+	delete(s.decks, id)
+	return nil
+}
@@ -0,0 +1,385 @@
+package rng
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxDeckTypeCards bounds the size of one deck of a user-defined type.
+const MaxDeckTypeCards = 10000
+
+// DeckEntry is a card outside a deck type's suit × value grid, present Count
+// times per deck (default 1). Code is its short code, if the type has codes.
+type DeckEntry struct {
+	Value string `json:"value"`
+	Suit  string `json:"suit,omitempty"`
+	Count int    `json:"count,omitempty"`
+	Code  string `json:"code,omitempty"`
+}
+
+// DeckType defines a kind of deck: every suit × value combination (Copies
+// times, default 1) followed by the extra cards. Jokers are only added when
+// requested. Template names a card in text output, with {value} and {suit}
+// placeholders (default "{value} of {suit}"); cards without a suit are named
+// by their value alone.
+//
+// ValueCodes and SuitCodes, parallel to Values and Suits, give grid cards a
+// short code through CodeTemplate (default "{value}{suit}"); extra cards and
+// jokers carry their own codes.
+type DeckType struct {
+	Name         string      `json:"name"`
+	Suits        []string    `json:"suits,omitempty"`
+	Values       []string    `json:"values,omitempty"`
+	Copies       int         `json:"copies,omitempty"`
+	Extra        []DeckEntry `json:"extra,omitempty"`
+	Jokers       []DeckEntry `json:"jokers,omitempty"`
+	Reversible   bool        `json:"reversible,omitempty"` // cards may be dealt upside down (tarot)
+	Template     string      `json:"template,omitempty"`
+	SuitCodes    []string    `json:"suit_codes,omitempty"`
+	ValueCodes   []string    `json:"value_codes,omitempty"`
+	CodeTemplate string      `json:"code_template,omitempty"`
+
+	glyph func(Card) (string, bool) // Unicode character, built-in types only
+
+	indexOnce sync.Once
+	idx       *deckIndex
+}
+
+func numberNames(from, to int) []string {
+	names := make([]string, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		names = append(names, strconv.Itoa(i))
+	}
+	return names
+}
+
+// deckEntries lists suit cards outside the grid; codes, if given, are
+// parallel to values.
+func deckEntries(values []string, suit string, count int, codes ...string) []DeckEntry {
+	entries := make([]DeckEntry, len(values))
+	for i, v := range values {
+		entries[i] = DeckEntry{Value: v, Suit: suit, Count: count}
+		if i < len(codes) {
+			entries[i].Code = codes[i]
+		}
+	}
+	return entries
+}
+
+var (
+	frenchSuits     = []string{"Hearts", "Diamonds", "Clubs", "Spades"}
+	frenchSuitCodes = []string{"H", "D", "C", "S"}
+	standardJokers  = []DeckEntry{{Value: "Joker", Suit: "Red", Code: "RJ"}, {Value: "Joker", Suit: "Black", Code: "BJ"}}
+	unoColors       = []string{"Red", "Yellow", "Green", "Blue"}
+)
+
+var deckTypes = map[string]*DeckType{
+	"standard": {
+		Name:       "standard",
+		Suits:      frenchSuits,
+		Values:     []string{"Ace", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten", "Jack", "Queen", "King"},
+		Jokers:     standardJokers,
+		SuitCodes:  frenchSuitCodes,
+		ValueCodes: []string{"A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "J", "Q", "K"},
+		glyph:      frenchGlyph,
+	},
+	// Piquet (also skat or belote): 32 cards, sevens up.
+	"piquet": {
+		Name:       "piquet",
+		Suits:      frenchSuits,
+		Values:     []string{"Seven", "Eight", "Nine", "Ten", "Jack", "Queen", "King", "Ace"},
+		Jokers:     standardJokers,
+		SuitCodes:  frenchSuitCodes,
+		ValueCodes: []string{"7", "8", "9", "10", "J", "Q", "K", "A"},
+		glyph:      frenchGlyph,
+	},
+	// Euchre: 24 cards, nines up.
+	"euchre": {
+		Name:       "euchre",
+		Suits:      frenchSuits,
+		Values:     []string{"Nine", "Ten", "Jack", "Queen", "King", "Ace"},
+		Jokers:     standardJokers,
+		SuitCodes:  frenchSuitCodes,
+		ValueCodes: []string{"9", "10", "J", "Q", "K", "A"},
+		glyph:      frenchGlyph,
+	},
+	// Pinochle: two copies of each card from nine up, 48 cards.
+	"pinochle": {
+		Name:       "pinochle",
+		Suits:      frenchSuits,
+		Values:     []string{"Nine", "Ten", "Jack", "Queen", "King", "Ace"},
+		Copies:     2,
+		SuitCodes:  frenchSuitCodes,
+		ValueCodes: []string{"9", "10", "J", "Q", "K", "A"},
+		glyph:      frenchGlyph,
+	},
+	// Spanish baraja: 40 cards, with the two comodines as jokers. Codes use
+	// the printed numbers (sota 10, caballo 11, rey 12).
+	"spanish": {
+		Name:       "spanish",
+		Suits:      []string{"Oros", "Copas", "Espadas", "Bastos"},
+		Values:     []string{"As", "Dos", "Tres", "Cuatro", "Cinco", "Seis", "Siete", "Sota", "Caballo", "Rey"},
+		Jokers:     []DeckEntry{{Value: "Comodín", Count: 2, Code: "J"}},
+		Template:   "{value} de {suit}",
+		SuitCodes:  []string{"O", "C", "E", "B"},
+		ValueCodes: []string{"1", "2", "3", "4", "5", "6", "7", "10", "11", "12"},
+	},
+	// Italian regional pattern: 40 cards, numbered 1 to 10.
+	"italian": {
+		Name:       "italian",
+		Suits:      []string{"Coppe", "Denari", "Spade", "Bastoni"},
+		Values:     []string{"Asso", "Due", "Tre", "Quattro", "Cinque", "Sei", "Sette", "Fante", "Cavallo", "Re"},
+		Template:   "{value} di {suit}",
+		SuitCodes:  []string{"C", "D", "S", "B"},
+		ValueCodes: numberNames(1, 10),
+	},
+	// Tarot: 22 major arcana (no suit, codes M0 to M21) and 56 minor arcana.
+	"tarot": {
+		Name:   "tarot",
+		Suits:  []string{"Wands", "Cups", "Swords", "Pentacles"},
+		Values: []string{"Ace", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten", "Page", "Knight", "Queen", "King"},
+		Extra: deckEntries([]string{
+			"The Fool", "The Magician", "The High Priestess", "The Empress", "The Emperor",
+			"The Hierophant", "The Lovers", "The Chariot", "Strength", "The Hermit",
+			"Wheel of Fortune", "Justice", "The Hanged Man", "Death", "Temperance",
+			"The Devil", "The Tower", "The Star", "The Moon", "The Sun", "Judgement", "The World",
+		}, "", 1, prefixed("M", numberNames(0, 21))...),
+		Reversible: true,
+		SuitCodes:  []string{"W", "C", "S", "P"},
+		ValueCodes: []string{"A", "2", "3", "4", "5", "6", "7", "8", "9", "10", "P", "N", "Q", "K"},
+	},
+	// Uno: 108 cards.
+	"uno": {
+		Name:   "uno",
+		Suits:  unoColors,
+		Values: append(numberNames(1, 9), "Skip", "Reverse", "Draw Two"),
+		Copies: 2,
+		Extra: []DeckEntry{
+			{Value: "0", Suit: "Red", Code: "R0"}, {Value: "0", Suit: "Yellow", Code: "Y0"},
+			{Value: "0", Suit: "Green", Code: "G0"}, {Value: "0", Suit: "Blue", Code: "B0"},
+			{Value: "Wild", Count: 4, Code: "W"}, {Value: "Wild Draw Four", Count: 4, Code: "W4"},
+		},
+		Template:     "{suit} {value}",
+		SuitCodes:    []string{"R", "Y", "G", "B"},
+		ValueCodes:   append(numberNames(1, 9), "S", "R", "D2"),
+		CodeTemplate: "{suit}{value}",
+	},
+	// Mahjong: 144 tiles including flowers and seasons. Codes follow the
+	// usual tenhou notation (1p, 9s, 5m, 1z-7z for honors) with 1f-8f for
+	// flowers and seasons.
+	"mahjong": {
+		Name:   "mahjong",
+		Suits:  []string{"Dots", "Bamboo", "Characters"},
+		Values: numberNames(1, 9),
+		Copies: 4,
+		Extra: slices.Concat(
+			deckEntries([]string{"East", "South", "West", "North"}, "Wind", 4, "1z", "2z", "3z", "4z"),
+			deckEntries([]string{"White", "Green", "Red"}, "Dragon", 4, "5z", "6z", "7z"),
+			deckEntries([]string{"Plum", "Orchid", "Chrysanthemum", "Bamboo"}, "Flower", 1, "1f", "2f", "3f", "4f"),
+			deckEntries([]string{"Spring", "Summer", "Autumn", "Winter"}, "Season", 1, "5f", "6f", "7f", "8f"),
+		),
+		Template:   "{value} {suit}",
+		SuitCodes:  []string{"p", "s", "m"},
+		ValueCodes: numberNames(1, 9),
+		glyph:      mahjongGlyph,
+	},
+	// Double-six dominoes: 28 tiles, the lower end as the suit.
+	"dominoes": {
+		Name:     "dominoes",
+		Extra:    dominoes(6),
+		Template: "{suit}|{value}",
+		glyph:    dominoGlyph,
+	},
+}
+
+func prefixed(prefix string, names []string) []string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = prefix + n
+	}
+	return out
+}
+
+func dominoes(max int) []DeckEntry {
+	var tiles []DeckEntry
+	for a := 0; a <= max; a++ {
+		for b := a; b <= max; b++ {
+			tiles = append(tiles, DeckEntry{Value: strconv.Itoa(b), Suit: strconv.Itoa(a), Code: strconv.Itoa(a) + strconv.Itoa(b)})
+		}
+	}
+	return tiles
+}
+
+// LookupDeckType returns a built-in deck type by name.
+func LookupDeckType(name string) (*DeckType, bool) {
+	t, ok := deckTypes[name]
+	return t, ok
+}
+
+// DeckTypeNames lists the built-in deck types, sorted.
+func DeckTypeNames() []string {
+	names := make([]string, 0, len(deckTypes))
+	for name := range deckTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseDeckType decodes and validates a user-supplied JSON deck definition.
+func ParseDeckType(data []byte) (*DeckType, error) {
+	var t DeckType
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&t); err != nil {
+		return nil, errors.New("deck definition must be a JSON object with suits, values, copies, extra, jokers, reversible and template")
+	}
+	if t.Name == "" {
+		t.Name = "custom"
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate checks a deck type's names and counts.
+func (t *DeckType) Validate() error {
+	name := func(s string) error {
+		if strings.TrimSpace(s) == "" || len(s) > 64 {
+			return errors.New("card values and suits must be 1 to 64 characters")
+		}
+		return nil
+	}
+	for _, list := range [][]string{t.Suits, t.Values} {
+		for _, s := range list {
+			if err := name(s); err != nil {
+				return err
+			}
+		}
+	}
+	if (len(t.Suits) == 0) != (len(t.Values) == 0) {
+		return errors.New("suits and values must be given together")
+	}
+	if t.Copies < 0 || t.Copies > 100 {
+		return errors.New("copies must be between 1 and 100")
+	}
+	for _, e := range append(append([]DeckEntry(nil), t.Extra...), t.Jokers...) {
+		if err := name(e.Value); err != nil {
+			return err
+		}
+		if e.Suit != "" {
+			if err := name(e.Suit); err != nil {
+				return err
+			}
+		}
+		if e.Count < 0 || e.Count > 100 {
+			return errors.New("extra card counts must be between 1 and 100")
+		}
+	}
+	if len(t.Template) > 64 || len(t.CodeTemplate) > 64 {
+		return errors.New("templates must be at most 64 characters")
+	}
+
+	// Check the size before anything below builds the deck.
+	size := t.Size(true)
+	if size < 1 {
+		return errors.New("deck definition has no cards")
+	}
+	if size > MaxDeckTypeCards {
+		return fmt.Errorf("deck definition may have at most %d cards", MaxDeckTypeCards)
+	}
+	return t.validateCodes()
+}
+
+func entryCount(e DeckEntry) int {
+	if e.Count == 0 {
+		return 1
+	}
+	return e.Count
+}
+
+// Size returns the number of cards in one deck of this type.
+func (t *DeckType) Size(jokers bool) int {
+	copies := t.Copies
+	if copies == 0 {
+		copies = 1
+	}
+	n := len(t.Suits) * len(t.Values) * copies
+	for _, e := range t.Extra {
+		n += entryCount(e)
+	}
+	if jokers {
+		for _, e := range t.Jokers {
+			n += entryCount(e)
+		}
+	}
+	return n
+}
+
+// Build returns numDecks unshuffled decks of this type.
+func (t *DeckType) Build(numDecks int, jokers bool) []Card {
+	copies := t.Copies
+	if copies == 0 {
+		copies = 1
+	}
+	deck := make([]Card, 0, numDecks*t.Size(jokers))
+
+	addEntries := func(entries []DeckEntry) {
+		for _, e := range entries {
+			for i := 0; i < entryCount(e); i++ {
+				deck = append(deck, Card{Value: e.Value, Suit: e.Suit})
+			}
+		}
+	}
+	for d := 0; d < numDecks; d++ {
+		for _, suit := range t.Suits {
+			for _, v := range t.Values {
+				for i := 0; i < copies; i++ {
+					deck = append(deck, Card{Value: v, Suit: suit})
+				}
+			}
+		}
+		addEntries(t.Extra)
+		if jokers {
+			addEntries(t.Jokers)
+		}
+	}
+	return deck
+}
+
+// CardName names a card for text output.
+func (t *DeckType) CardName(c Card) string {
+	name := c.Value
+	if c.Suit != "" {
+		tmpl := t.Template
+		if tmpl == "" {
+			tmpl = "{value} of {suit}"
+		}
+		name = strings.NewReplacer("{value}", c.Value, "{suit}", c.Suit).Replace(tmpl)
+	}
+	if c.Reversed {
+		name += " (reversed)"
+	}
+	return name
+}
+
+// Orient gives every card an independent, uniformly random orientation.
+func Orient(r io.Reader, h *Health, cards []Card) error {
+	bits := make([]byte, (len(cards)+7)/8)
+	if err := readFull(r, h, bits); err != nil {
+		return err
+	}
+	for i := range cards {
+		cards[i].Reversed = bits[i/8]>>(i%8)&1 == 1
+	}
+	return nil
+}
@@ -0,0 +1,511 @@
+package rng
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits keep a single expression from draining the entropy source.
+const (
+	maxDiceExprLen  = 256
+	maxDiceTerms    = 20
+	maxDicePerGroup = 100
+	maxDiceSides    = 1_000_000
+	maxDiceRolls    = 1000
+	maxDiceChain    = 100
+	maxDiceConstant = 1_000_000
+)
+
+// ErrTooManyDice is returned by Roll when rerolls and explosions push an
+// expression past the per-expression roll limit.
+var ErrTooManyDice = fmt.Errorf("too many dice rolled; max is %d", maxDiceRolls)
+
+// ErrDiceOutOfRange is returned by Roll when the total overflows.
+var ErrDiceOutOfRange = errors.New("dice result out of range")
+
+// Die is a single rolled die in a DiceTerm.
+type Die struct {
+	Value    int   `json:"value"`
+	Kept     bool  `json:"kept"`
+	Exploded bool  `json:"exploded,omitempty"` // added by an exploding die
+	Rerolls  []int `json:"rerolls,omitempty"`  // discarded values, in roll order
+}
+
+// DiceTerm is one operand of a dice expression with its result.
+// Dice is empty for constant terms.
+type DiceTerm struct {
+	Op       string `json:"op"`
+	Notation string `json:"notation"`
+	Dice     []Die  `json:"dice,omitempty"`
+	Value    int    `json:"value"`
+}
+
+// DiceResult is the outcome of rolling a DiceExpr.
+type DiceResult struct {
+	Expression string     `json:"expression"`
+	Terms      []DiceTerm `json:"terms"`
+	Total      int        `json:"total"`
+}
+
+// DiceExpr is a parsed dice expression, ready to roll.
+type DiceExpr struct {
+	source string
+	terms  []diceTerm
+}
+
+type diceTerm struct {
+	op       byte // '+', '-' or '*'
+	notation string
+	constant int
+	dice     *diceSpec
+}
+
+type diceCompare struct {
+	op    byte // '=', '<' (at most) or '>' (at least)
+	value int
+}
+
+func (c diceCompare) match(v int) bool {
+	switch c.op {
+	case '<':
+		return v <= c.value
+	case '>':
+		return v >= c.value
+	}
+	return v == c.value
+}
+
+type diceSpec struct {
+	count      int
+	sides      int
+	fudge      bool
+	keep       byte // 0, 'k' (keep) or 'd' (drop)
+	high       bool // keep/drop applies to the highest dice
+	keepN      int
+	explode    *diceCompare
+	reroll     *diceCompare
+	rerollOnce bool
+}
+
+func (d *diceSpec) faces() (lo, hi int) {
+	if d.fudge {
+		return -1, 1
+	}
+	return 1, d.sides
+}
+
+// ParseDice parses standard dice notation.
+//
+// Accepts:
+//
+//	"d20", "4d6+2", "2d8-1d4", "3d6*2"
+//	"4d6kh3", "4d6k3" (keep highest), "2d20kl1" (keep lowest),
+//	"4d6dl1", "4d6d1" (drop lowest), "5d10dh2" (drop highest)
+//	"6d6!" (explode on max), "6d6!>5" (explode on 5 or more)
+//	"2d6r1", "2d6r<2" (reroll until above 2), "2d6ro1" (reroll once)
+//	"4dF" (Fudge/Fate dice, -1..1), "d%" (percentile, 1..100)
+//
+// Whitespace is ignored. '*' binds tighter than '+' and '-'.
+func ParseDice(expr string) (*DiceExpr, error) {
+	s := strings.ToLower(strings.Join(strings.Fields(expr), ""))
+	if s == "" {
+		return nil, errors.New("dice expression is empty")
+	}
+	if len(s) > maxDiceExprLen {
+		return nil, errors.New("dice expression too long")
+	}
+
+	p := &diceParser{s: s}
+	out := &DiceExpr{source: s}
+	op := byte('+')
+	for {
+		start := p.pos
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		term.op = op
+		term.notation = s[start:p.pos]
+		out.terms = append(out.terms, term)
+		if len(out.terms) > maxDiceTerms {
+			return nil, fmt.Errorf("too many terms; max is %d", maxDiceTerms)
+		}
+
+		if p.done() {
+			break
+		}
+		op = p.next()
+		if op != '+' && op != '-' && op != '*' {
+			return nil, p.errorf(p.pos-1, "unexpected %q", op)
+		}
+		if p.done() {
+			return nil, errors.New("invalid dice expression: missing term after operator")
+		}
+	}
+
+	// Reject obviously oversized expressions before consuming any entropy.
+	rolls := 0
+	for _, t := range out.terms {
+		if t.dice != nil {
+			rolls += t.dice.count
+		}
+	}
+	if rolls > maxDiceRolls {
+		return nil, ErrTooManyDice
+	}
+	return out, nil
+}
+
+type diceParser struct {
+	s   string
+	pos int
+}
+
+func (p *diceParser) done() bool { return p.pos >= len(p.s) }
+
+func (p *diceParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *diceParser) next() byte {
+	b := p.peek()
+	p.pos++
+	return b
+}
+
+func (p *diceParser) errorf(pos int, format string, args ...any) error {
+	return fmt.Errorf("invalid dice expression at position %d: %s", pos+1, fmt.Sprintf(format, args...))
+}
+
+// number reads an optional run of digits; ok is false when there are none.
+func (p *diceParser) number(max int) (n int, ok bool, err error) {
+	start := p.pos
+	for !p.done() && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, false, nil
+	}
+	n, convErr := strconv.Atoi(p.s[start:p.pos])
+	if convErr != nil || n > max {
+		return 0, false, p.errorf(start, "number too large; max is %d", max)
+	}
+	return n, true, nil
+}
+
+func (p *diceParser) term() (diceTerm, error) {
+	start := p.pos
+	n, hasN, err := p.number(maxDiceConstant)
+	if err != nil {
+		return diceTerm{}, err
+	}
+
+	if p.peek() != 'd' {
+		if !hasN {
+			if p.done() {
+				return diceTerm{}, errors.New("invalid dice expression: unexpected end")
+			}
+			return diceTerm{}, p.errorf(p.pos, "unexpected %q", p.peek())
+		}
+		return diceTerm{constant: n}, nil
+	}
+	p.pos++ // 'd'
+
+	spec := &diceSpec{count: 1}
+	if hasN {
+		if n < 1 || n > maxDicePerGroup {
+			return diceTerm{}, p.errorf(start, "dice count must be between 1 and %d", maxDicePerGroup)
+		}
+		spec.count = n
+	}
+
+	switch p.peek() {
+	case '%':
+		p.pos++
+		spec.sides = 100
+	case 'f':
+		p.pos++
+		spec.fudge = true
+		spec.sides = 3
+	default:
+		sidesAt := p.pos
+		sides, ok, err := p.number(maxDiceSides)
+		if err != nil {
+			return diceTerm{}, err
+		}
+		if !ok {
+			return diceTerm{}, p.errorf(sidesAt, "expected number of sides, '%%' or 'F'")
+		}
+		if sides < 1 {
+			return diceTerm{}, p.errorf(sidesAt, "dice must have at least 1 side")
+		}
+		spec.sides = sides
+	}
+
+	if err := p.modifiers(spec); err != nil {
+		return diceTerm{}, err
+	}
+	return diceTerm{dice: spec}, nil
+}
+
+func (p *diceParser) modifiers(spec *diceSpec) error {
+	lo, hi := spec.faces()
+	for !p.done() {
+		at := p.pos
+		switch p.peek() {
+		case 'k', 'd':
+			if spec.keep != 0 {
+				return p.errorf(at, "only one keep/drop modifier is allowed")
+			}
+			spec.keep = p.next()
+			spec.high = spec.keep == 'k'
+			switch p.peek() {
+			case 'h':
+				p.pos++
+				spec.high = true
+			case 'l':
+				p.pos++
+				spec.high = false
+			}
+			n, ok, err := p.number(maxDicePerGroup)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				n = 1
+			}
+			if n > spec.count {
+				return p.errorf(at, "cannot keep or drop %d of %d dice", n, spec.count)
+			}
+			spec.keepN = n
+
+		case '!':
+			if spec.explode != nil {
+				return p.errorf(at, "only one explode modifier is allowed")
+			}
+			p.pos++
+			cmp, err := p.compare(diceCompare{op: '=', value: hi})
+			if err != nil {
+				return err
+			}
+			if allMatch(cmp, lo, hi) {
+				return p.errorf(at, "explode condition matches every face")
+			}
+			spec.explode = &cmp
+
+		case 'r':
+			if spec.reroll != nil {
+				return p.errorf(at, "only one reroll modifier is allowed")
+			}
+			p.pos++
+			if p.peek() == 'o' {
+				p.pos++
+				spec.rerollOnce = true
+			}
+			cmp, err := p.compare(diceCompare{op: '=', value: lo})
+			if err != nil {
+				return err
+			}
+			if allMatch(cmp, lo, hi) {
+				return p.errorf(at, "reroll condition matches every face")
+			}
+			spec.reroll = &cmp
+
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// compare reads an optional "<n", ">n", "=n" or "n" compare point.
+func (p *diceParser) compare(def diceCompare) (diceCompare, error) {
+	cmp := def
+	explicit := false
+	if b := p.peek(); b == '<' || b == '>' || b == '=' {
+		cmp.op = p.next()
+		explicit = true
+		if p.peek() == '=' { // accept "<=" / ">=" as aliases
+			p.pos++
+		}
+	} else if b < '0' || b > '9' {
+		return def, nil
+	}
+
+	// Fudge compare points may be negative, but only after an explicit
+	// operator so "2d6r1-3" still reads as a subtraction.
+	neg := false
+	if explicit && p.peek() == '-' {
+		p.pos++
+		neg = true
+	}
+	at := p.pos
+	n, ok, err := p.number(maxDiceSides)
+	if err != nil {
+		return cmp, err
+	}
+	if !ok {
+		return cmp, p.errorf(at, "expected a number")
+	}
+	if neg {
+		n = -n
+	}
+	cmp.value = n
+	return cmp, nil
+}
+
+func allMatch(cmp diceCompare, lo, hi int) bool {
+	for v := lo; v <= hi; v++ {
+		if !cmp.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Roll evaluates the expression, rolling every die with UniformInt32.
+func (e *DiceExpr) Roll(r io.Reader, h *Health) (*DiceResult, error) {
+	res := &DiceResult{Expression: e.source}
+	rolls := 0
+
+	// Accumulate in int64 so overflow is caught the same way on every
+	// platform; the total must still fit in an int.
+	var total, product int64
+	ok := true
+	for i, t := range e.terms {
+		term := DiceTerm{Op: string(t.op), Notation: t.notation, Value: t.constant}
+		if t.dice != nil {
+			dice, value, err := t.dice.roll(r, h, &rolls)
+			if err != nil {
+				return nil, err
+			}
+			term.Dice, term.Value = dice, value
+		}
+		res.Terms = append(res.Terms, term)
+
+		switch {
+		case i == 0:
+			product = int64(term.Value)
+		case t.op == '*':
+			product, ok = mulInt64(product, int64(term.Value))
+		default:
+			total, ok = addInt64(total, product)
+			product = int64(term.Value)
+			if t.op == '-' {
+				product = -product
+			}
+		}
+		if !ok {
+			return nil, ErrDiceOutOfRange
+		}
+	}
+	sum, ok := addInt64(total, product)
+	if !ok || int64(int(sum)) != sum {
+		return nil, ErrDiceOutOfRange
+	}
+	res.Total = int(sum)
+	return res, nil
+}
+
+// addInt64 and mulInt64 report false instead of wrapping around.
+func addInt64(a, b int64) (int64, bool) {
+	c := a + b
+	return c, (c > a) == (b > 0)
+}
+
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	return c, c/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+}
+
+func (d *diceSpec) roll(r io.Reader, h *Health, rolls *int) ([]Die, int, error) {
+	lo, hi := d.faces()
+	rollOne := func() (int, error) {
+		*rolls++
+		if *rolls > maxDiceRolls {
+			return 0, ErrTooManyDice
+		}
+		v, err := UniformInt32(r, h, lo, hi)
+		return int(v), err
+	}
+
+	rollDie := func() (Die, error) {
+		v, err := rollOne()
+		if err != nil {
+			return Die{}, err
+		}
+		die := Die{Value: v, Kept: true}
+		for n := 0; d.reroll != nil && d.reroll.match(die.Value); n++ {
+			if (d.rerollOnce && n == 1) || n == maxDiceChain {
+				break
+			}
+			die.Rerolls = append(die.Rerolls, die.Value)
+			if die.Value, err = rollOne(); err != nil {
+				return Die{}, err
+			}
+		}
+		return die, nil
+	}
+
+	dice := make([]Die, 0, d.count)
+	for i := 0; i < d.count; i++ {
+		die, err := rollDie()
+		if err != nil {
+			return nil, 0, err
+		}
+		dice = append(dice, die)
+
+		for n := 0; d.explode != nil && d.explode.match(die.Value) && n < maxDiceChain; n++ {
+			if die, err = rollDie(); err != nil {
+				return nil, 0, err
+			}
+			die.Exploded = true
+			dice = append(dice, die)
+		}
+	}
+
+	if d.keep != 0 {
+		order := make([]int, len(dice))
+		for i := range order {
+			order[i] = i
+		}
+		// Stable so ties select the earliest-rolled die.
+		sort.SliceStable(order, func(a, b int) bool {
+			if d.high {
+				return dice[order[a]].Value > dice[order[b]].Value
+			}
+			return dice[order[a]].Value < dice[order[b]].Value
+		})
+
+		marked := make(map[int]bool, d.keepN)
+		for _, i := range order[:d.keepN] {
+			marked[i] = true
+		}
+		for i := range dice {
+			if d.keep == 'k' {
+				dice[i].Kept = marked[i]
+			} else {
+				dice[i].Kept = !marked[i]
+			}
+		}
+	}
+
+	sum := 0
+	for _, die := range dice {
+		if die.Kept {
+			sum += die.Value
+		}
+	}
+	return dice, sum, nil
+}
@@ -0,0 +1,45 @@
+package rng
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// UniformFloat64 returns a uniform float in [0, 1) built from the top 53
+// bits of a uint64 (every representable multiple of 2^-53 is equally likely).
+func UniformFloat64(r io.Reader, h *Health) (float64, error) {
+	var buf [8]byte
+	if err := readFull(r, h, buf[:]); err != nil {
+		return 0, err
+	}
+	return float64(binary.BigEndian.Uint64(buf[:])>>11) / (1 << 53), nil
+}
+
+// openUniform returns a uniform float in (0, 1], safe to pass to math.Log.
+func openUniform(r io.Reader, h *Health) (float64, error) {
+	u, err := UniformFloat64(r, h)
+	return 1 - u, err
+}
+
+// NormalFloat64 returns a standard normal variate (Box–Muller transform).
+func NormalFloat64(r io.Reader, h *Health) (float64, error) {
+	u1, err := openUniform(r, h)
+	if err != nil {
+		return 0, err
+	}
+	u2, err := UniformFloat64(r, h)
+	if err != nil {
+		return 0, err
+	}
+	return math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2), nil
+}
+
+// ExpFloat64 returns an exponential variate with rate 1.
+func ExpFloat64(r io.Reader, h *Health) (float64, error) {
+	u, err := openUniform(r, h)
+	if err != nil {
+		return 0, err
+	}
+	return -math.Log(u), nil
+}
@@ -0,0 +1,350 @@
+package rng
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"slices"
+)
+
+// EarthRadiusMeters is the mean Earth radius (IUGG) used for radius sampling.
+const EarthRadiusMeters = 6_371_008.8
+
+// LatLon is a point on the sphere in degrees.
+type LatLon struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+func toRad(deg float64) float64 { return deg * math.Pi / 180 }
+func toDeg(rad float64) float64 { return rad * 180 / math.Pi }
+
+// normalizeLon wraps a longitude into [-180, 180).
+func normalizeLon(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
+
+// RandomOnSphere returns a point uniformly distributed over the whole sphere.
+// Latitude is drawn so that sin(lat) is uniform (Archimedes' hat-box theorem);
+// naive uniform latitude would crowd the poles.
+func RandomOnSphere(r io.Reader, h *Health) (LatLon, error) {
+	return RandomInBBox(r, h, -90, -180, 90, 180)
+}
+
+// ValidateBBox checks a box for RandomInBBox, so callers can reject it before
+// reading any entropy.
+func ValidateBBox(south, west, north, east float64) error {
+	if south < -90 || north > 90 || south > north {
+		return errors.New("latitudes must satisfy -90 <= south <= north <= 90")
+	}
+	if west < -180 || west > 180 || east < -180 || east > 180 {
+		return errors.New("longitudes must be between -180 and 180")
+	}
+	return nil
+}
+
+// RandomInBBox returns a point uniformly distributed (by area) inside a
+// latitude/longitude box. west > east means the box crosses the antimeridian.
+func RandomInBBox(r io.Reader, h *Health, south, west, north, east float64) (LatLon, error) {
+	if err := ValidateBBox(south, west, north, east); err != nil {
+		return LatLon{}, err
+	}
+
+	width := east - west
+	if width < 0 {
+		width += 360
+	}
+	if west == -180 && east == 180 {
+		width = 360
+	}
+
+	u, err := UniformFloat64(r, h)
+	if err != nil {
+		return LatLon{}, err
+	}
+	v, err := UniformFloat64(r, h)
+	if err != nil {
+		return LatLon{}, err
+	}
+
+	zs, zn := math.Sin(toRad(south)), math.Sin(toRad(north))
+	lat := toDeg(math.Asin(zs + u*(zn-zs)))
+	lon := normalizeLon(west + v*width)
+	return LatLon{Lat: lat, Lon: lon}, nil
+}
+
+// ValidateRadius checks a center and radius for RandomInRadius, so callers
+// can reject them before reading any entropy.
+func ValidateRadius(center LatLon, radiusMeters float64) error {
+	if center.Lat < -90 || center.Lat > 90 || center.Lon < -180 || center.Lon > 180 {
+		return errors.New("center must be a valid latitude/longitude")
+	}
+	if radiusMeters <= 0 || math.IsNaN(radiusMeters) {
+		return errors.New("radius must be positive")
+	}
+	return nil
+}
+
+// RandomInRadius returns a point uniformly distributed (by area) within
+// radiusMeters great-circle distance of center.
+func RandomInRadius(r io.Reader, h *Health, center LatLon, radiusMeters float64) (LatLon, error) {
+	if err := ValidateRadius(center, radiusMeters); err != nil {
+		return LatLon{}, err
+	}
+
+	// On a sphere the cap area above angle θ is proportional to 1 - cos θ,
+	// so cos θ is uniform in [cos δ, 1].
+	delta := math.Min(radiusMeters/EarthRadiusMeters, math.Pi)
+	u, err := UniformFloat64(r, h)
+	if err != nil {
+		return LatLon{}, err
+	}
+	v, err := UniformFloat64(r, h)
+	if err != nil {
+		return LatLon{}, err
+	}
+	cosTheta := 1 - u*(1-math.Cos(delta))
+	theta := math.Acos(cosTheta)
+	bearing := 2 * math.Pi * v
+
+	// Destination point given distance and bearing from center.
+	lat1, lon1 := toRad(center.Lat), toRad(center.Lon)
+	lat2 := math.Asin(math.Sin(lat1)*cosTheta + math.Cos(lat1)*math.Sin(theta)*math.Cos(bearing))
+	lon2 := lon1 + math.Atan2(math.Sin(bearing)*math.Sin(theta)*math.Cos(lat1),
+		cosTheta-math.Sin(lat1)*math.Sin(lat2))
+	return LatLon{Lat: toDeg(lat2), Lon: normalizeLon(toDeg(lon2))}, nil
+}
+
+// Polygon is a GeoJSON polygon: an outer ring followed by optional holes,
+// each ring a list of [lon, lat] positions.
+type Polygon [][][2]float64
+
+// ParseGeoJSONPolygon accepts a GeoJSON Polygon geometry, or a Feature whose
+// geometry is a Polygon.
+func ParseGeoJSONPolygon(data []byte) (Polygon, error) {
+	var obj struct {
+		Type        string          `json:"type"`
+		Coordinates [][][]float64   `json:"coordinates"`
+		Geometry    json.RawMessage `json:"geometry"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, errors.New("invalid GeoJSON")
+	}
+	if obj.Type == "Feature" {
+		return ParseGeoJSONPolygon(obj.Geometry)
+	}
+	if obj.Type != "Polygon" {
+		return nil, errors.New("GeoJSON must be a Polygon or a Feature with a Polygon geometry")
+	}
+	if len(obj.Coordinates) == 0 {
+		return nil, errors.New("polygon has no rings")
+	}
+
+	poly := make(Polygon, len(obj.Coordinates))
+	for i, ring := range obj.Coordinates {
+		if len(ring) < 4 {
+			return nil, errors.New("polygon rings need at least 4 positions")
+		}
+		for _, pos := range ring {
+			if len(pos) < 2 || pos[0] < -180 || pos[0] > 180 || pos[1] < -90 || pos[1] > 90 {
+				return nil, errors.New("polygon positions must be valid [lon, lat] pairs")
+			}
+			poly[i] = append(poly[i], [2]float64{pos[0], pos[1]})
+		}
+	}
+	return poly, nil
+}
+
+// Contains reports whether the point is inside the outer ring and outside
+// every hole. Edges are straight lines in lon/lat, as in RFC 7946.
+func (p Polygon) Contains(pt LatLon) bool {
+	if len(p) == 0 || !ringContains(p[0], pt) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if ringContains(hole, pt) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains is the even-odd ray casting test.
+func ringContains(ring [][2]float64, pt LatLon) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > pt.Lat) != (yj > pt.Lat) &&
+			pt.Lon < (xj-xi)*(pt.Lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// PolygonSampler draws area-uniform points from a polygon without
+// rejection. The polygon is cut at every vertex latitude into slabs; within
+// a slab the edges are straight and do not cross, so consecutive pairs of
+// them (even-odd, as in Contains) bound trapezoids in lon/lat. A point is a
+// trapezoid picked by spherical area, then a latitude from the trapezoid's
+// width times cos(lat), then a longitude uniform across the width.
+type PolygonSampler struct {
+	traps []trapezoid
+	cum   []float64 // running total of the trapezoid areas
+}
+
+// trapezoid spans [lat0, lat1] (radians) between a left and a right edge,
+// given as their longitudes (degrees) at both latitudes.
+type trapezoid struct {
+	lat0, lat1         float64
+	xl0, xl1, xr0, xr1 float64
+}
+
+// NewPolygonSampler decomposes the polygon once, so every Sample costs
+// three uniform draws. Polygons crossing the antimeridian are not supported.
+func NewPolygonSampler(poly Polygon) (*PolygonSampler, error) {
+	if len(poly) == 0 {
+		return nil, errors.New("polygon has no rings")
+	}
+
+	type edge struct{ x0, y0, x1, y1 float64 } // y0 < y1
+	var edges []edge
+	var lats []float64
+	for _, ring := range poly {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[j], ring[i]
+			lats = append(lats, a[1])
+			if a[1] == b[1] {
+				continue
+			}
+			if a[1] > b[1] {
+				a, b = b, a
+			}
+			edges = append(edges, edge{a[0], a[1], b[0], b[1]})
+		}
+	}
+	slices.Sort(lats)
+	lats = slices.Compact(lats)
+	slices.SortFunc(edges, func(a, b edge) int { return cmp.Compare(a.y0, b.y0) })
+
+	xAt := func(e edge, y float64) float64 { return e.x0 + (e.x1-e.x0)*(y-e.y0)/(e.y1-e.y0) }
+	s := &PolygonSampler{}
+	var active []edge
+	next, total := 0, 0.0
+	for k := 0; k+1 < len(lats); k++ {
+		lo, hi := lats[k], lats[k+1]
+		for ; next < len(edges) && edges[next].y0 <= lo; next++ {
+			active = append(active, edges[next])
+		}
+		active = slices.DeleteFunc(active, func(e edge) bool { return e.y1 <= lo })
+
+		// No vertex lies strictly inside the slab, so every active edge spans it.
+		mid := (lo + hi) / 2
+		slices.SortFunc(active, func(a, b edge) int { return cmp.Compare(xAt(a, mid), xAt(b, mid)) })
+		const eps = 1e-9
+		for i := 0; i+1 < len(active); i++ {
+			if xAt(active[i], lo) > xAt(active[i+1], lo)+eps || xAt(active[i], hi) > xAt(active[i+1], hi)+eps {
+				return nil, errors.New("polygon edges must not cross")
+			}
+		}
+		if len(active)%2 != 0 {
+			return nil, errors.New("polygon edges must not cross")
+		}
+
+		for i := 0; i < len(active); i += 2 {
+			l, r := active[i], active[i+1]
+			t := trapezoid{
+				lat0: toRad(lo), lat1: toRad(hi),
+				xl0: xAt(l, lo), xl1: xAt(l, hi), xr0: xAt(r, lo), xr1: xAt(r, hi),
+			}
+			if area := t.area(); area > 0 {
+				total += area
+				s.traps = append(s.traps, t)
+				s.cum = append(s.cum, total)
+			}
+		}
+	}
+	if total == 0 {
+		return nil, errors.New("polygon has no area")
+	}
+	return s, nil
+}
+
+// The trapezoid's width in degrees is linear in the latitude offset x from
+// its middle m: wm + d·x for x in [-half, half].
+func (t trapezoid) shape() (m, half, wm, d float64) {
+	m, half = (t.lat0+t.lat1)/2, (t.lat1-t.lat0)/2
+	w0, w1 := t.xr0-t.xl0, t.xr1-t.xl1
+	return m, half, (w0 + w1) / 2, (w1 - w0) / (2 * half)
+}
+
+// area is ∫ (wm + d·x) cos(m+x) dx over the trapezoid, in a form that stays
+// accurate for thin slabs.
+func (t trapezoid) area() float64 {
+	m, half, wm, d := t.shape()
+	// sin h - h cos h cancels badly for small h; use its series there.
+	odd := math.Sin(half) - half*math.Cos(half)
+	if half < 1e-2 {
+		h3 := half * half * half
+		odd = h3/3 - h3*half*half/30 + h3*h3*half/840
+	}
+	return math.Max(0, 2*wm*math.Cos(m)*math.Sin(half)-2*d*math.Sin(m)*odd)
+}
+
+// Sample returns a point uniformly distributed (by area) inside the polygon.
+func (s *PolygonSampler) Sample(r io.Reader, h *Health) (LatLon, error) {
+	u, err := UniformFloat64(r, h)
+	if err != nil {
+		return LatLon{}, err
+	}
+	i, _ := slices.BinarySearch(s.cum, u*s.cum[len(s.cum)-1])
+	t := s.traps[min(i, len(s.traps)-1)]
+
+	// Latitude: invert the CDF of (wm + d·x) cos(m+x) by bisection.
+	v, err := UniformFloat64(r, h)
+	if err != nil {
+		return LatLon{}, err
+	}
+	m, half, wm, d := t.shape()
+	antideriv := func(x float64) float64 {
+		return wm*math.Sin(m+x) + d*(x*math.Sin(m+x)+math.Cos(m+x))
+	}
+	base := antideriv(-half)
+	target := v * (antideriv(half) - base)
+	lo, hi := -half, half
+	for i := 0; i < 64; i++ {
+		mid := (lo + hi) / 2
+		if antideriv(mid)-base < target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	x := (lo + hi) / 2
+
+	w, err := UniformFloat64(r, h)
+	if err != nil {
+		return LatLon{}, err
+	}
+	frac := (x + half) / (2 * half)
+	left := t.xl0 + (t.xl1-t.xl0)*frac
+	right := t.xr0 + (t.xr1-t.xr0)*frac
+	return LatLon{Lat: toDeg(m + x), Lon: left + w*(right-left)}, nil
+}
+
+// RandomInPolygon returns a point uniformly distributed (by area) inside the
+// polygon. Callers drawing many points should keep a NewPolygonSampler.
+func RandomInPolygon(r io.Reader, h *Health, poly Polygon) (LatLon, error) {
+	s, err := NewPolygonSampler(poly)
+	if err != nil {
+		return LatLon{}, err
+	}
+	return s.Sample(r, h)
+}
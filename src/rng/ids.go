@@ -0,0 +1,112 @@
+package rng
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// CrockfordAlphabet is Crockford's base32 alphabet (no I, L, O or U), used by
+// ULIDs and base32 tokens.
+const CrockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NanoIDAlphabet is the default NanoID alphabet (URL-safe, 64 symbols).
+const NanoIDAlphabet = "useandom-26T198340PX75pxJACKVERYMINDBUSHWOLF_GQZbfghjklqvwyzrict"
+
+var crockford = base32.NewEncoding(CrockfordAlphabet).WithPadding(base32.NoPadding)
+
+// ULIDGenerator produces ULIDs: a 48-bit Unix millisecond timestamp and 80
+// random bits, 26 Crockford base32 characters. In monotonic mode an ID in
+// the same millisecond as the previous one (or an earlier one, if the clock
+// went backwards) reuses the previous timestamp and increments the random
+// part, so IDs from one generator always sort in creation order.
+type ULIDGenerator struct {
+	mu     sync.Mutex
+	lastMs uint64
+	last   [10]byte
+}
+
+// ErrULIDOverflow is returned when monotonic mode exhausts the random part
+// within one millisecond.
+var ErrULIDOverflow = errors.New("ulid random component overflow")
+
+// Next returns a new ULID.
+func (g *ULIDGenerator) Next(r io.Reader, h *Health, now time.Time, monotonic bool) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(now.UnixMilli())
+	var entropy [10]byte
+
+	if monotonic && ms <= g.lastMs {
+		ms = g.lastMs
+		entropy = g.last
+		i := len(entropy) - 1
+		for ; i >= 0; i-- {
+			entropy[i]++
+			if entropy[i] != 0 {
+				break
+			}
+		}
+		if i < 0 {
+			return "", ErrULIDOverflow
+		}
+	} else if err := readFull(r, h, entropy[:]); err != nil {
+		return "", err
+	}
+
+	g.lastMs, g.last = ms, entropy
+
+	var b [16]byte
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(b[6:], entropy[:])
+	return encodeULID(b), nil
+}
+
+// encodeULID encodes 128 bits as 26 base32 characters, most significant
+// first (the leading character carries only 3 bits).
+func encodeULID(b [16]byte) string {
+	out := make([]byte, 26)
+	var acc uint32
+	bits := 2 // pad 128 bits to 130 so they split into 5-bit groups
+	pos := 0
+	for _, v := range b {
+		acc = acc<<8 | uint32(v)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[pos] = CrockfordAlphabet[(acc>>bits)&31]
+			pos++
+		}
+	}
+	return string(out)
+}
+
+// RandomToken reads n random bytes and encodes them as "base32" (Crockford,
+// unpadded) or "base64url" (unpadded).
+func RandomToken(r io.Reader, h *Health, n int, encoding string) (string, error) {
+	if n < 1 {
+		return "", errors.New("token size must be at least 1 byte")
+	}
+
+	var encode func([]byte) string
+	switch encoding {
+	case "base32":
+		encode = crockford.EncodeToString
+	case "base64url":
+		encode = base64.RawURLEncoding.EncodeToString
+	default:
+		return "", errors.New("encoding must be base32 or base64url")
+	}
+
+	buf := make([]byte, n)
+	if err := readFull(r, h, buf); err != nil {
+		return "", err
+	}
+	return encode(buf), nil
+}
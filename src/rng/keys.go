@@ -0,0 +1,221 @@
+package rng
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// KeyPair is a generated key pair. Private is one of ed25519.PrivateKey,
+// *ecdh.PrivateKey, *ecdsa.PrivateKey or *rsa.PrivateKey.
+type KeyPair struct {
+	Type    string
+	Private crypto.PrivateKey
+	Public  crypto.PublicKey
+}
+
+// GenerateKeyPair creates a key pair of the given type ("ed25519", "x25519",
+// "wireguard", "p256", "p384" or "rsa") with all secret material read from r.
+// bits is only used for RSA (2048, 3072 or 4096).
+func GenerateKeyPair(r io.Reader, h *Health, kind string, bits int) (*KeyPair, error) {
+	switch kind {
+	case "ed25519":
+		seed := make([]byte, ed25519.SeedSize)
+		if err := readFull(r, h, seed); err != nil {
+			return nil, err
+		}
+		priv := ed25519.NewKeyFromSeed(seed)
+		return &KeyPair{Type: kind, Private: priv, Public: priv.Public()}, nil
+
+	case "x25519", "wireguard":
+		b := make([]byte, 32)
+		if err := readFull(r, h, b); err != nil {
+			return nil, err
+		}
+		if kind == "wireguard" {
+			// Clamp as `wg genkey` does.
+			b[0] &= 248
+			b[31] = (b[31] & 127) | 64
+		}
+		priv, err := ecdh.X25519().NewPrivateKey(b)
+		if err != nil {
+			return nil, err
+		}
+		return &KeyPair{Type: kind, Private: priv, Public: priv.PublicKey()}, nil
+
+	case "p256", "p384":
+		curve, ecdhCurve := elliptic.P256(), ecdh.P256()
+		if kind == "p384" {
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		}
+		return generateECDSA(r, h, kind, curve, ecdhCurve)
+
+	case "rsa":
+		if bits != 2048 && bits != 3072 && bits != 4096 {
+			return nil, errors.New("rsa bits must be 2048, 3072 or 4096")
+		}
+		return generateRSA(r, h, kind, bits)
+	}
+	return nil, errors.New("key type must be ed25519, x25519, wireguard, p256, p384 or rsa")
+}
+
+// generateECDSA draws the private scalar uniformly from [1, n-1] and derives
+// the public point through crypto/ecdh.
+func generateECDSA(r io.Reader, h *Health, kind string, curve elliptic.Curve, ecdhCurve ecdh.Curve) (*KeyPair, error) {
+	n := curve.Params().N
+	d, err := UniformBigInt(r, h, new(big.Int).Sub(n, big.NewInt(1)))
+	if err != nil {
+		return nil, err
+	}
+	d.Add(d, big.NewInt(1))
+
+	size := (curve.Params().BitSize + 7) / 8
+	ecdhKey, err := ecdhCurve.NewPrivateKey(d.FillBytes(make([]byte, size)))
+	if err != nil {
+		return nil, err
+	}
+	pub := ecdhKey.PublicKey().Bytes() // 0x04 || X || Y
+
+	priv := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: curve,
+			X:     new(big.Int).SetBytes(pub[1 : 1+size]),
+			Y:     new(big.Int).SetBytes(pub[1+size:]),
+		},
+		D: d,
+	}
+	return &KeyPair{Type: kind, Private: priv, Public: &priv.PublicKey}, nil
+}
+
+// generateRSA builds the key from primes searched for in r. rsa.GenerateKey
+// cannot be used: since Go 1.26 it ignores its reader and draws from
+// crypto/rand unless GODEBUG=cryptocustomrand=1.
+func generateRSA(r io.Reader, h *Health, kind string, bits int) (*KeyPair, error) {
+	e := big.NewInt(65537)
+	one := big.NewInt(1)
+	for {
+		p, err := randomPrime(r, h, bits/2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := randomPrime(r, h, bits-bits/2)
+		if err != nil {
+			return nil, err
+		}
+		// Primes this close would make N easy to factor (FIPS 186-5 B.3.3).
+		if new(big.Int).Sub(p, q).BitLen() <= bits/2-100 {
+			continue
+		}
+
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d := new(big.Int).ModInverse(e, phi)
+		if d == nil {
+			continue // e shares a factor with p-1 or q-1
+		}
+
+		priv := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: new(big.Int).Mul(p, q), E: int(e.Int64())},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+		priv.Precompute()
+		if err := priv.Validate(); err != nil {
+			return nil, err
+		}
+		return &KeyPair{Type: kind, Private: priv, Public: &priv.PublicKey}, nil
+	}
+}
+
+// randomPrime returns a prime of exactly bits bits whose top two bits are set,
+// so that the product of two has exactly twice the bits. It searches upward
+// from one random start rather than reading a fresh candidate each time, to
+// spend less hardware entropy.
+func randomPrime(r io.Reader, h *Health, bits int) (*big.Int, error) {
+	buf := make([]byte, (bits+7)/8)
+	two := big.NewInt(2)
+	for {
+		if err := readFull(r, h, buf); err != nil {
+			return nil, err
+		}
+		buf[0] &= 0xFF >> (8*len(buf) - bits)
+		p := new(big.Int).SetBytes(buf)
+		p.SetBit(p, bits-1, 1)
+		p.SetBit(p, bits-2, 1)
+		p.SetBit(p, 0, 1)
+		for ; p.BitLen() == bits; p.Add(p, two) {
+			if p.ProbablyPrime(20) {
+				return p, nil
+			}
+		}
+	}
+}
+
+// Encode renders the key pair as "pem" (PKCS#8 / PKIX), "openssh"
+// (OpenSSH private key and authorized_keys line) or "base64" (raw keys for
+// 25519 types, the ECDSA scalar and uncompressed point, DER for RSA).
+func (k *KeyPair) Encode(format string) (private, public string, err error) {
+	switch format {
+	case "pem":
+		privDER, err := x509.MarshalPKCS8PrivateKey(k.Private)
+		if err != nil {
+			return "", "", err
+		}
+		pubDER, err := x509.MarshalPKIXPublicKey(k.Public)
+		if err != nil {
+			return "", "", err
+		}
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER})),
+			string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})), nil
+
+	case "openssh":
+		if _, ok := k.Private.(*ecdh.PrivateKey); ok {
+			return "", "", errors.New("openssh format is not available for x25519 keys")
+		}
+		block, err := ssh.MarshalPrivateKey(k.Private, "")
+		if err != nil {
+			return "", "", err
+		}
+		sshPub, err := ssh.NewPublicKey(k.Public)
+		if err != nil {
+			return "", "", err
+		}
+		return string(pem.EncodeToMemory(block)),
+			strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub))), nil
+
+	case "base64":
+		var privRaw, pubRaw []byte
+		switch priv := k.Private.(type) {
+		case ed25519.PrivateKey:
+			privRaw, pubRaw = priv.Seed(), priv.Public().(ed25519.PublicKey)
+		case *ecdh.PrivateKey:
+			privRaw, pubRaw = priv.Bytes(), priv.PublicKey().Bytes()
+		case *ecdsa.PrivateKey:
+			ecdhKey, err := priv.ECDH()
+			if err != nil {
+				return "", "", err
+			}
+			privRaw, pubRaw = ecdhKey.Bytes(), ecdhKey.PublicKey().Bytes()
+		case *rsa.PrivateKey:
+			if privRaw, err = x509.MarshalPKCS8PrivateKey(priv); err != nil {
+				return "", "", err
+			}
+			if pubRaw, err = x509.MarshalPKIXPublicKey(&priv.PublicKey); err != nil {
+				return "", "", err
+			}
+		}
+		return base64.StdEncoding.EncodeToString(privRaw), base64.StdEncoding.EncodeToString(pubRaw), nil
+	}
+	return "", "", errors.New("format must be pem, openssh or base64")
+}
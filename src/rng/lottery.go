@@ -0,0 +1,104 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+// SampleDistinct returns k distinct integers from [min, max], in draw order.
+//
+// Uses Floyd's algorithm, which needs exactly k random integers and O(k)
+// memory regardless of the range size, followed by a Fisher–Yates shuffle of
+// the k picks so that the order is uniformly random as well.
+func SampleDistinct(r io.Reader, h *Health, min int, max int, k int) ([]int, error) {
+	if min > max {
+		return nil, errors.New("min must be less than or equal to max")
+	}
+	if k < 0 || k > max-min+1 {
+		return nil, errors.New("count must be between 0 and the size of the range")
+	}
+
+	picked := make([]int, 0, k)
+	seen := make(map[int]struct{}, k)
+	for j := max - k + 1; j <= max; j++ {
+		t, err := UniformInt32(r, h, min, j)
+		if err != nil {
+			return nil, err
+		}
+
+		v := int(t)
+		if _, dup := seen[v]; dup {
+			v = j
+		}
+		seen[v] = struct{}{}
+		picked = append(picked, v)
+	}
+
+	if err := Shuffle(r, h, picked); err != nil {
+		return nil, err
+	}
+	return picked, nil
+}
+
+// LotteryPool describes one set of balls in a lottery draw.
+// A pool with Shared set draws from the same balls as the pool before it
+// (e.g. a bonus ball drawn from the balls left after the main draw).
+type LotteryPool struct {
+	Name   string `json:"name"`
+	Min    int    `json:"min"`
+	Max    int    `json:"max"`
+	Count  int    `json:"count"`
+	Shared bool   `json:"shared,omitempty"`
+}
+
+// LotteryPreset returns the pools of a named lottery game.
+func LotteryPreset(name string) ([]LotteryPool, bool) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "6/49", "649", "lotto":
+		return []LotteryPool{
+			{Name: "main", Min: 1, Max: 49, Count: 6},
+			{Name: "bonus", Min: 1, Max: 49, Count: 1, Shared: true},
+		}, true
+	case "powerball":
+		return []LotteryPool{
+			{Name: "white", Min: 1, Max: 69, Count: 5},
+			{Name: "powerball", Min: 1, Max: 26, Count: 1},
+		}, true
+	case "euromillions":
+		return []LotteryPool{
+			{Name: "main", Min: 1, Max: 50, Count: 5},
+			{Name: "lucky_stars", Min: 1, Max: 12, Count: 2},
+		}, true
+	}
+	return nil, false
+}
+
+// DrawLottery draws every pool and returns the numbers per pool, in draw order.
+// Consecutive shared pools are drawn together so they never repeat a ball.
+func DrawLottery(r io.Reader, h *Health, pools []LotteryPool) ([][]int, error) {
+	out := make([][]int, len(pools))
+
+	for start := 0; start < len(pools); {
+		end := start + 1
+		total := pools[start].Count
+		for end < len(pools) && pools[end].Shared {
+			if pools[end].Min != pools[start].Min || pools[end].Max != pools[start].Max {
+				return nil, errors.New("shared pools must have the same range")
+			}
+			total += pools[end].Count
+			end++
+		}
+
+		balls, err := SampleDistinct(r, h, pools[start].Min, pools[start].Max, total)
+		if err != nil {
+			return nil, err
+		}
+		for i := start; i < end; i++ {
+			out[i] = balls[:pools[i].Count]
+			balls = balls[pools[i].Count:]
+		}
+		start = end
+	}
+	return out, nil
+}
@@ -0,0 +1,76 @@
+package rng
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// BIP-39 English wordlist, 2048 words.
+// https://github.com/bitcoin/bips/blob/master/bip-0039/english.txt
+
+//go:embed wordlists/bip39_english.txt
+var bip39EnglishWordlist string
+
+var bip39English = sync.OnceValue(func() []string {
+	return strings.Fields(bip39EnglishWordlist)
+})
+
+// BIP39English returns the BIP-39 English wordlist.
+func BIP39English() []string { return bip39English() }
+
+// MnemonicFromEntropy encodes 16, 20, 24, 28 or 32 bytes of entropy as a
+// BIP-39 mnemonic: the entropy followed by the first len/4 bits of its
+// SHA-256 hash, split into 11-bit word indexes.
+func MnemonicFromEntropy(entropy []byte) (string, error) {
+	n := len(entropy)
+	if n < 16 || n > 32 || n%4 != 0 {
+		return "", errors.New("entropy must be 16, 20, 24, 28 or 32 bytes")
+	}
+
+	hash := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), hash[0])
+	totalBits := n*8 + n/4
+
+	words := BIP39English()
+	out := make([]string, totalBits/11)
+	for i := range out {
+		idx := 0
+		for b := i * 11; b < (i+1)*11; b++ {
+			bit := (data[b/8] >> (7 - b%8)) & 1
+			idx = idx<<1 | int(bit)
+		}
+		out[i] = words[idx]
+	}
+	return strings.Join(out, " "), nil
+}
+
+// NewMnemonic draws fresh entropy for a 12, 15, 18, 21 or 24 word mnemonic.
+func NewMnemonic(r io.Reader, h *Health, words int) (mnemonic string, entropy []byte, err error) {
+	if words < 12 || words > 24 || words%3 != 0 {
+		return "", nil, errors.New("words must be 12, 15, 18, 21 or 24")
+	}
+
+	entropy = make([]byte, words*4/3)
+	if err := readFull(r, h, entropy); err != nil {
+		return "", nil, err
+	}
+	mnemonic, err = MnemonicFromEntropy(entropy)
+	return mnemonic, entropy, err
+}
+
+// MnemonicSeed derives the 64-byte BIP-39 seed: PBKDF2-HMAC-SHA512 with 2048
+// iterations over the NFKD-normalized mnemonic, salted with "mnemonic" plus
+// the NFKD-normalized passphrase.
+func MnemonicSeed(mnemonic, passphrase string) []byte {
+	password := norm.NFKD.String(mnemonic)
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+	return pbkdf2.Key([]byte(password), []byte(salt), 2048, 64, sha512.New)
+}
@@ -0,0 +1,99 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/netip"
+)
+
+// RandomMAC returns a random unicast MAC address. Without a prefix the
+// address is locally administered (bit 1 of the first octet set, bit 0
+// clear). With a prefix (e.g. a 3-byte OUI) the prefix is kept verbatim and
+// only the remaining octets are random.
+func RandomMAC(r io.Reader, h *Health, prefix []byte) (net.HardwareAddr, error) {
+	if len(prefix) > 5 {
+		return nil, errors.New("mac prefix must be at most 5 bytes")
+	}
+	if len(prefix) > 0 && prefix[0]&0x01 != 0 {
+		return nil, errors.New("mac prefix must be unicast (first octet even)")
+	}
+
+	mac := make(net.HardwareAddr, 6)
+	copy(mac, prefix)
+	if err := readFull(r, h, mac[len(prefix):]); err != nil {
+		return nil, err
+	}
+	if len(prefix) == 0 {
+		mac[0] = (mac[0] | 0x02) &^ 0x01
+	}
+	return mac, nil
+}
+
+// RandomIP returns a uniformly random host address inside prefix.
+//
+// IPv4 excludes the network and broadcast addresses, except in /31
+// (point-to-point, RFC 3021) and /32 networks. IPv6 has no broadcast but
+// excludes the all-zeros Subnet-Router anycast address, except in a /128.
+func RandomIP(r io.Reader, h *Health, prefix netip.Prefix) (netip.Addr, error) {
+	if !prefix.IsValid() {
+		return netip.Addr{}, errors.New("invalid cidr")
+	}
+	prefix = prefix.Masked()
+
+	base := prefix.Addr()
+	hostBits := base.BitLen() - prefix.Bits()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+
+	// Candidate host offsets are [first, first+n).
+	first, n := big.NewInt(0), new(big.Int).Set(size)
+	switch {
+	case base.Is4() && hostBits >= 2:
+		first.SetInt64(1)
+		n.Sub(n, big.NewInt(2))
+	case base.Is6() && hostBits >= 1:
+		first.SetInt64(1)
+		n.Sub(n, big.NewInt(1))
+	}
+
+	off, err := UniformBigInt(r, h, n)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	off.Add(off, first)
+
+	raw := base.AsSlice()
+	v := new(big.Int).SetBytes(raw)
+	v.Add(v, off)
+	addr, _ := netip.AddrFromSlice(v.FillBytes(make([]byte, len(raw))))
+	return addr, nil
+}
+
+// RandomPorts returns k distinct ports from [min, max] that are not in
+// exclude, in draw order.
+func RandomPorts(r io.Reader, h *Health, min, max int, exclude map[int]bool, k int) ([]int, error) {
+	if min < 1 || max > 65535 || min > max {
+		return nil, errors.New("port range must satisfy 1 <= min <= max <= 65535")
+	}
+
+	candidates := make([]int, 0, max-min+1)
+	for p := min; p <= max; p++ {
+		if !exclude[p] {
+			candidates = append(candidates, p)
+		}
+	}
+	if k < 1 || k > len(candidates) {
+		return nil, errors.New("count must be between 1 and the number of available ports")
+	}
+
+	idx, err := SampleDistinct(r, h, 0, len(candidates)-1, k)
+	if err != nil {
+		return nil, err
+	}
+	ports := make([]int, k)
+	for i, j := range idx {
+		ports[i] = candidates[j]
+	}
+	return ports, nil
+}
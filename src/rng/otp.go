@@ -0,0 +1,172 @@
+package rng
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var otpBase32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// OTPSpec describes a TOTP (RFC 6238) or HOTP (RFC 4226) credential.
+type OTPSpec struct {
+	Type      string // "totp" or "hotp"
+	Issuer    string
+	Account   string
+	Algorithm string // "SHA1", "SHA256" or "SHA512"
+	Digits    int    // 6, 7 or 8
+	Period    int    // seconds per TOTP step
+	Counter   uint64 // initial HOTP counter
+}
+
+// Validate checks the spec's enumerated fields.
+func (s OTPSpec) Validate() error {
+	if s.Type != "totp" && s.Type != "hotp" {
+		return errors.New("type must be totp or hotp")
+	}
+	if _, err := otpHash(s.Algorithm); err != nil {
+		return err
+	}
+	if s.Digits < 6 || s.Digits > 8 {
+		return errors.New("digits must be 6, 7 or 8")
+	}
+	if s.Type == "totp" && (s.Period < 1 || s.Period > 3600) {
+		return errors.New("period must be between 1 and 3600 seconds")
+	}
+	return nil
+}
+
+func otpHash(algorithm string) (func() hash.Hash, error) {
+	switch algorithm {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+	return nil, errors.New("algorithm must be SHA1, SHA256 or SHA512")
+}
+
+// NewOTPSecret reads n random bytes and returns them as unpadded RFC 4648
+// base32, the encoding authenticator apps expect.
+func NewOTPSecret(r io.Reader, h *Health, n int) (string, error) {
+	if n < 16 || n > 64 {
+		return "", errors.New("secret size must be between 16 and 64 bytes")
+	}
+	buf := make([]byte, n)
+	if err := readFull(r, h, buf); err != nil {
+		return "", err
+	}
+	return otpBase32.EncodeToString(buf), nil
+}
+
+// DecodeOTPSecret parses a base32 secret, ignoring case, spaces and padding.
+func DecodeOTPSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.NewReplacer(" ", "", "=", "").Replace(secret))
+	key, err := otpBase32.DecodeString(s)
+	if err != nil || len(key) == 0 {
+		return nil, errors.New("secret must be base32")
+	}
+	return key, nil
+}
+
+// OTPURI builds the otpauth:// provisioning URI for a secret (Key URI Format
+// used by Google Authenticator and compatible apps).
+func OTPURI(secret string, spec OTPSpec) (string, error) {
+	if err := spec.Validate(); err != nil {
+		return "", err
+	}
+	if spec.Account == "" {
+		return "", errors.New("account must not be empty")
+	}
+
+	label := spec.Account
+	if spec.Issuer != "" {
+		label = spec.Issuer + ":" + spec.Account
+	}
+
+	q := url.Values{}
+	q.Set("secret", secret)
+	if spec.Issuer != "" {
+		q.Set("issuer", spec.Issuer)
+	}
+	q.Set("algorithm", spec.Algorithm)
+	q.Set("digits", strconv.Itoa(spec.Digits))
+	if spec.Type == "totp" {
+		q.Set("period", strconv.Itoa(spec.Period))
+	} else {
+		q.Set("counter", strconv.FormatUint(spec.Counter, 10))
+	}
+
+	u := url.URL{Scheme: "otpauth", Host: spec.Type, Path: "/" + label, RawQuery: q.Encode()}
+	return u.String(), nil
+}
+
+// OTPCode computes the HOTP value for a counter (RFC 4226 dynamic
+// truncation); TOTP uses the time step as the counter.
+func OTPCode(key []byte, algorithm string, digits int, counter uint64) (string, error) {
+	newHash, err := otpHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(newHash, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	off := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[off:off+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	code := strconv.FormatUint(uint64(value%mod), 10)
+	return strings.Repeat("0", digits-len(code)) + code, nil
+}
+
+// VerifyOTP checks code against the counters within window steps of the
+// current one (the TOTP time step at now, or spec.Counter for HOTP, where
+// only later counters are tried). It returns the matching offset.
+func VerifyOTP(key []byte, code string, spec OTPSpec, now time.Time, window int) (ok bool, offset int, err error) {
+	if err := spec.Validate(); err != nil {
+		return false, 0, err
+	}
+	if window < 0 || window > 10 {
+		return false, 0, errors.New("window must be between 0 and 10")
+	}
+
+	base := spec.Counter
+	from := 0
+	if spec.Type == "totp" {
+		base = uint64(now.Unix()) / uint64(spec.Period)
+		from = -window
+	}
+
+	for off := from; off <= window; off++ {
+		if off < 0 && uint64(-off) > base {
+			continue
+		}
+		want, err := OTPCode(key, spec.Algorithm, spec.Digits, base+uint64(int64(off)))
+		if err != nil {
+			return false, 0, err
+		}
+		if hmac.Equal([]byte(want), []byte(code)) {
+			return true, off, nil
+		}
+	}
+	return false, 0, nil
+}
@@ -0,0 +1,76 @@
+package rng
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// OutcomeTable holds mutually exclusive outcomes as exact integer weights
+// over a common denominator. The weights always sum to Den.
+type OutcomeTable struct {
+	Weights []*big.Int
+	Den     *big.Int
+}
+
+// NewOutcomeTable scales the probabilities to their least common denominator
+// and checks that they sum to exactly 1 (i.e. 100%).
+func NewOutcomeTable(probs []Probability) (*OutcomeTable, error) {
+	if len(probs) == 0 {
+		return nil, errors.New("at least one outcome is required")
+	}
+
+	den := big.NewInt(1)
+	gcd := new(big.Int)
+	for _, p := range probs {
+		// lcm(a, b) = a / gcd(a, b) * b
+		gcd.GCD(nil, nil, den, p.Den)
+		den.Div(den, gcd).Mul(den, p.Den)
+	}
+
+	sum := new(big.Int)
+	weights := make([]*big.Int, len(probs))
+	for i, p := range probs {
+		w := new(big.Int).Div(den, p.Den)
+		w.Mul(w, p.Num)
+		weights[i] = w
+		sum.Add(sum, w)
+	}
+
+	if sum.Cmp(den) != 0 {
+		total := new(big.Rat).SetFrac(new(big.Int).Mul(sum, big.NewInt(100)), den)
+		return nil, fmt.Errorf("outcome percentages must sum to exactly 100 (got %s)",
+			total.FloatString(decimalPlaces(den)))
+	}
+	return &OutcomeTable{Weights: weights, Den: den}, nil
+}
+
+// decimalPlaces returns how many decimals a percentage over den needs
+// to print exactly, assuming den is 10^k or 100 * 10^k.
+func decimalPlaces(den *big.Int) int {
+	places := len(den.String()) - 3
+	if places < 0 {
+		return 0
+	}
+	return places
+}
+
+// Roll draws a uniform integer in [1, Den] and returns the index of the
+// outcome whose cumulative weight range contains it.
+func (t *OutcomeTable) Roll(r io.Reader, h *Health) (index int, roll *big.Int, err error) {
+	roll, err = UniformBigInt(r, h, t.Den)
+	if err != nil {
+		return 0, nil, err
+	}
+	roll.Add(roll, big.NewInt(1))
+
+	acc := new(big.Int)
+	for i, w := range t.Weights {
+		acc.Add(acc, w)
+		if roll.Cmp(acc) <= 0 {
+			return i, roll, nil
+		}
+	}
+	return 0, nil, errors.New("outcome weights do not cover the denominator")
+}
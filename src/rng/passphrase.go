@@ -0,0 +1,170 @@
+package rng
+
+import (
+	_ "embed"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"sync"
+)
+
+// EFF diceware wordlists, one word per line in dice-roll order.
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases
+
+//go:embed wordlists/eff_large_wordlist.txt
+var effLargeWordlist string
+
+//go:embed wordlists/eff_short_wordlist_2_0.txt
+var effShortWordlist string
+
+var wordlists = sync.OnceValue(func() map[string][]string {
+	return map[string][]string{
+		"large": strings.Fields(effLargeWordlist),
+		"short": strings.Fields(effShortWordlist),
+	}
+})
+
+// Wordlist returns an embedded wordlist by name ("large" or "short").
+func Wordlist(name string) ([]string, bool) {
+	words, ok := wordlists()[name]
+	return words, ok
+}
+
+// PassphraseSpec configures RandomPassphrase.
+type PassphraseSpec struct {
+	Words      []string // wordlist to draw from
+	Count      int      // number of words
+	Separator  string
+	Capitalize string // "none", "first", "upper" or "random" (first letter, coin flip per word)
+	Digits     int    // digits appended to one random word
+	Symbols    int    // symbols appended after the digits
+}
+
+// RandomPassphrase builds a diceware-style passphrase and returns it with
+// the entropy in bits of the generation process:
+//
+//	Count*log2(n)                                word choices, n = len(Words)
+//	                                             less hyphenated words if the
+//	                                             separator has "-"
+//	+ Count                                      if Capitalize is "random"
+//	+ log2(Count) + Digits*log2(10)
+//	  + Symbols*log2(len(symbols))               if anything is injected
+func RandomPassphrase(r io.Reader, h *Health, spec PassphraseSpec) (string, float64, error) {
+	if len(spec.Words) < 2 {
+		return "", 0, errors.New("wordlist must contain at least 2 words")
+	}
+	if spec.Count < 1 {
+		return "", 0, errors.New("word count must be at least 1")
+	}
+	if spec.Digits < 0 || spec.Symbols < 0 {
+		return "", 0, errors.New("digits and symbols must not be negative")
+	}
+	// Without a separator, different word choices can spell the same
+	// passphrase (sun+set, sunset), so the entropy would be overstated.
+	if spec.Separator == "" {
+		return "", 0, errors.New("separator must not be empty")
+	}
+
+	// With a hyphen in the separator, hyphenated words (t-shirt, yo-yo) blur
+	// the word boundaries in the same way, so they are left out.
+	list := spec.Words
+	if strings.Contains(spec.Separator, "-") {
+		list = withoutHyphens(list)
+	}
+
+	symbols := BuildCharset(false, false, false, true)
+	entropy := float64(spec.Count) * math.Log2(float64(len(list)))
+
+	words := make([]string, spec.Count)
+	for i := range words {
+		idx, err := UniformIndex(r, h, len(list))
+		if err != nil {
+			return "", 0, err
+		}
+		words[i] = list[idx]
+	}
+
+	switch spec.Capitalize {
+	case "", "none":
+	case "first":
+		for i, w := range words {
+			words[i] = capitalizeFirst(w)
+		}
+	case "upper":
+		for i, w := range words {
+			words[i] = strings.ToUpper(w)
+		}
+	case "random":
+		for i, w := range words {
+			flip, err := UniformIndex(r, h, 2)
+			if err != nil {
+				return "", 0, err
+			}
+			if flip == 1 {
+				words[i] = capitalizeFirst(w)
+			}
+		}
+		entropy += float64(spec.Count)
+	default:
+		return "", 0, errors.New("capitalize must be none, first, upper or random")
+	}
+
+	if spec.Digits+spec.Symbols > 0 {
+		target, err := UniformIndex(r, h, spec.Count)
+		if err != nil {
+			return "", 0, err
+		}
+
+		var block strings.Builder
+		for i := 0; i < spec.Digits; i++ {
+			d, err := UniformIndex(r, h, 10)
+			if err != nil {
+				return "", 0, err
+			}
+			block.WriteByte(byte('0' + d))
+		}
+		for i := 0; i < spec.Symbols; i++ {
+			s, err := UniformIndex(r, h, len(symbols))
+			if err != nil {
+				return "", 0, err
+			}
+			block.WriteByte(symbols[s])
+		}
+		words[target] += block.String()
+
+		entropy += math.Log2(float64(spec.Count)) +
+			float64(spec.Digits)*math.Log2(10) +
+			float64(spec.Symbols)*math.Log2(float64(len(symbols)))
+	}
+
+	return strings.Join(words, spec.Separator), entropy, nil
+}
+
+// withoutHyphens returns words without the hyphenated ones, or words itself
+// if there are none.
+func withoutHyphens(words []string) []string {
+	var out []string
+	for i, w := range words {
+		if strings.Contains(w, "-") {
+			if out == nil {
+				out = append(make([]string, 0, len(words)), words[:i]...)
+			}
+			continue
+		}
+		if out != nil {
+			out = append(out, w)
+		}
+	}
+	if out == nil {
+		return words
+	}
+	return out
+}
+
+func capitalizeFirst(w string) string {
+	if w == "" {
+		return w
+	}
+	return strings.ToUpper(w[:1]) + w[1:]
+}
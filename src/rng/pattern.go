@@ -0,0 +1,237 @@
+package rng
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Pattern limits.
+const (
+	MaxPatternLength = 1024 // placeholders and literals after expanding repetition
+	maxPatternRepeat = 1000
+	maxPatternDepth  = 10
+)
+
+var errPatternTooLong = errors.New("pattern expands to more than 1024 elements")
+
+const (
+	patternConsonants = "bcdfghjklmnpqrstvwxyz"
+	patternVowels     = "aeiou"
+
+	// Syllables are onset + vowel + optional coda, chosen for pronounceability.
+	syllableOnsets = "bdfghjklmnprstvz"
+	syllableCodas  = "lmnrst"
+)
+
+var syllables = func() []string {
+	var out []string
+	for _, on := range syllableOnsets {
+		for _, v := range patternVowels {
+			out = append(out, string(on)+string(v))
+			for _, co := range syllableCodas {
+				out = append(out, string(on)+string(v)+string(co))
+			}
+		}
+	}
+	return out
+}()
+
+// patternClasses maps placeholder characters to their choices.
+var patternClasses = func() map[rune][]string {
+	lower := "abcdefghijklmnopqrstuvwxyz"
+	upper := strings.ToUpper(lower)
+	digits := "0123456789"
+	symbols := string(BuildCharset(false, false, false, true))
+
+	classes := map[rune][]string{
+		'a': runeChoices(lower),
+		'A': runeChoices(upper),
+		'9': runeChoices(digits),
+		'#': runeChoices(digits),
+		'c': runeChoices(patternConsonants),
+		'C': runeChoices(strings.ToUpper(patternConsonants)),
+		'v': runeChoices(patternVowels),
+		'V': runeChoices(strings.ToUpper(patternVowels)),
+		'x': runeChoices(lower + digits),
+		'X': runeChoices(upper + digits),
+		'!': runeChoices(symbols),
+		'*': runeChoices(lower + upper + digits + symbols),
+		's': syllables,
+	}
+	capitalized := make([]string, len(syllables))
+	for i, s := range syllables {
+		capitalized[i] = capitalizeFirst(s)
+	}
+	classes['S'] = capitalized
+	return classes
+}()
+
+func runeChoices(s string) []string {
+	out := make([]string, 0, len(s))
+	for _, ch := range s {
+		out = append(out, string(ch))
+	}
+	return out
+}
+
+// Pattern is a compiled template. Each element is either a literal (one
+// choice) or a placeholder drawn uniformly from its choices.
+type Pattern struct {
+	elems [][]string
+}
+
+// ParsePattern compiles a template:
+//
+//	a A        lowercase / uppercase letter
+//	9 #        digit
+//	c C        lowercase / uppercase consonant
+//	v V        lowercase / uppercase vowel
+//	x X        lowercase / uppercase letter or digit
+//	!          symbol
+//	*          letter, digit or symbol
+//	s S        pronounceable syllable (S capitalized)
+//	\c         literal c
+//	'...'      literal run
+//	(...)      group
+//	{n}        repeat the previous placeholder, literal or group n times
+//
+// Any other character is a literal.
+func ParsePattern(s string) (*Pattern, error) {
+	p := &patternParser{s: []rune(s)}
+	elems, err := p.sequence(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf(p.pos, "unmatched ')'")
+	}
+	if len(elems) == 0 {
+		return nil, errors.New("pattern is empty")
+	}
+	return &Pattern{elems: elems}, nil
+}
+
+type patternParser struct {
+	s   []rune
+	pos int
+}
+
+func (p *patternParser) done() bool { return p.pos >= len(p.s) }
+
+func (p *patternParser) errorf(pos int, format string, args ...any) error {
+	return fmt.Errorf("invalid pattern at position %d: %s", pos+1, fmt.Sprintf(format, args...))
+}
+
+// sequence parses items until the end of input or a ')'.
+func (p *patternParser) sequence(depth int) ([][]string, error) {
+	if depth > maxPatternDepth {
+		return nil, p.errorf(p.pos, "groups nested too deeply")
+	}
+
+	var out [][]string
+	for !p.done() && p.s[p.pos] != ')' {
+		start := p.pos
+		var item [][]string
+
+		switch ch := p.s[p.pos]; ch {
+		case '(':
+			p.pos++
+			group, err := p.sequence(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if p.done() {
+				return nil, p.errorf(start, "unterminated group")
+			}
+			p.pos++ // ')'
+			item = group
+		case '\'':
+			end := p.pos + 1
+			for end < len(p.s) && p.s[end] != '\'' {
+				end++
+			}
+			if end >= len(p.s) {
+				return nil, p.errorf(start, "unterminated literal")
+			}
+			for _, lit := range p.s[p.pos+1 : end] {
+				item = append(item, []string{string(lit)})
+			}
+			p.pos = end + 1
+		case '\\':
+			if p.pos+1 >= len(p.s) {
+				return nil, p.errorf(start, "trailing backslash")
+			}
+			item = [][]string{{string(p.s[p.pos+1])}}
+			p.pos += 2
+		case '{', '}':
+			return nil, p.errorf(start, "repetition must follow a placeholder, literal or group")
+		default:
+			if choices, ok := patternClasses[ch]; ok {
+				item = [][]string{choices}
+			} else {
+				item = [][]string{{string(ch)}}
+			}
+			p.pos++
+		}
+
+		n, err := p.repeat()
+		if err != nil {
+			return nil, err
+		}
+		if len(out)+n*len(item) > MaxPatternLength {
+			return nil, errPatternTooLong
+		}
+		for i := 0; i < n; i++ {
+			out = append(out, item...)
+		}
+	}
+	return out, nil
+}
+
+// repeat reads an optional {n}; it returns 1 when there is none.
+func (p *patternParser) repeat() (int, error) {
+	if p.done() || p.s[p.pos] != '{' {
+		return 1, nil
+	}
+	start := p.pos
+	end := p.pos + 1
+	for end < len(p.s) && p.s[end] != '}' {
+		end++
+	}
+	if end >= len(p.s) {
+		return 0, p.errorf(start, "unterminated repetition")
+	}
+	n, err := strconv.Atoi(string(p.s[p.pos+1 : end]))
+	if err != nil || n < 0 || n > maxPatternRepeat {
+		return 0, p.errorf(start, "repetition must be {n} with 0 <= n <= %d", maxPatternRepeat)
+	}
+	p.pos = end + 1
+	return n, nil
+}
+
+// EntropyBits returns the entropy of the generation process: the sum of
+// log2(choices) over all placeholders.
+func (pt *Pattern) EntropyBits() float64 {
+	bits := 0.0
+	for _, choices := range pt.elems {
+		bits += math.Log2(float64(len(choices)))
+	}
+	return bits
+}
+
+// Generate fills every placeholder with a uniform choice.
+func (pt *Pattern) Generate(r io.Reader, h *Health) (string, error) {
+	var out strings.Builder
+	for _, choices := range pt.elems {
+		idx, err := UniformIndex(r, h, len(choices))
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(choices[idx])
+	}
+	return out.String(), nil
+}
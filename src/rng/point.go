@@ -0,0 +1,93 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// MaxPointDimension bounds the dimension of RandomUnitVector and friends.
+const MaxPointDimension = 1000
+
+func checkDimension(dim int) error {
+	if dim < 1 || dim > MaxPointDimension {
+		return errors.New("dimension must be between 1 and 1000")
+	}
+	return nil
+}
+
+// RandomUnitVector returns a point uniformly distributed on the surface of
+// the unit sphere in dim dimensions (a normalized standard normal vector).
+func RandomUnitVector(r io.Reader, h *Health, dim int) ([]float64, error) {
+	if err := checkDimension(dim); err != nil {
+		return nil, err
+	}
+
+	v := make([]float64, dim)
+	for {
+		norm := 0.0
+		for i := range v {
+			x, err := NormalFloat64(r, h)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = x
+			norm += x * x
+		}
+		if norm == 0 {
+			continue // vanishingly rare; the direction is undefined
+		}
+		norm = math.Sqrt(norm)
+		for i := range v {
+			v[i] /= norm
+		}
+		return v, nil
+	}
+}
+
+// RandomInBall returns a point uniformly distributed inside the dim-dimensional
+// ball of the given radius: a uniform direction scaled by radius * U^(1/dim).
+func RandomInBall(r io.Reader, h *Health, dim int, radius float64) ([]float64, error) {
+	if radius <= 0 || math.IsNaN(radius) || math.IsInf(radius, 0) {
+		return nil, errors.New("radius must be positive")
+	}
+
+	v, err := RandomUnitVector(r, h, dim)
+	if err != nil {
+		return nil, err
+	}
+	u, err := UniformFloat64(r, h)
+	if err != nil {
+		return nil, err
+	}
+
+	scale := radius * math.Pow(u, 1/float64(dim))
+	for i := range v {
+		v[i] *= scale
+	}
+	return v, nil
+}
+
+// RandomInSimplex returns a point uniformly distributed inside the standard
+// dim-simplex {x : x_i >= 0, sum(x) <= 1}. It normalizes dim+1 exponential
+// variates (a flat Dirichlet sample) and drops the last coordinate.
+func RandomInSimplex(r io.Reader, h *Health, dim int) ([]float64, error) {
+	if err := checkDimension(dim); err != nil {
+		return nil, err
+	}
+
+	e := make([]float64, dim+1)
+	sum := 0.0
+	for i := range e {
+		x, err := ExpFloat64(r, h)
+		if err != nil {
+			return nil, err
+		}
+		e[i] = x
+		sum += x
+	}
+	for i := range e {
+		e[i] /= sum
+	}
+	return e[:dim], nil
+}
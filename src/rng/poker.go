@@ -0,0 +1,278 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"slices"
+)
+
+// PokerGame describes how a poker variant is dealt and how hands are made.
+type PokerGame struct {
+	Hole     int  // hole cards per player
+	Board    bool // five community cards (flop, turn, river)
+	MustUse  int  // hole cards every hand must use; 0 = any
+	BoardUse int  // board cards every hand must use when MustUse is set
+}
+
+var pokerGames = map[string]PokerGame{
+	"holdem": {Hole: 2, Board: true},
+	"omaha":  {Hole: 4, Board: true, MustUse: 2, BoardUse: 3},
+	"draw":   {Hole: 5},
+}
+
+// LookupPokerGame returns a poker variant: "holdem", "omaha" or "draw"
+// (five-card draw, dealt without the draw round).
+func LookupPokerGame(name string) (PokerGame, bool) {
+	g, ok := pokerGames[name]
+	return g, ok
+}
+
+// PokerDeal is one dealt hand: hole cards per seat, the board and the cards
+// burned before the flop, turn and river.
+type PokerDeal struct {
+	Hands  [][]Card
+	Board  []Card
+	Burned []Card
+}
+
+// MaxPlayers returns how many players one 52-card deck can deal to.
+func (g PokerGame) MaxPlayers(burn bool) int {
+	rest := 0
+	if g.Board {
+		rest = 5
+		if burn {
+			rest += 3
+		}
+	}
+	return (52 - rest) / g.Hole
+}
+
+// DealPoker shuffles one standard deck and deals it as a dealer would: hole
+// cards one at a time around the table, then, for board games, an optional
+// burn before each of the flop, turn and river.
+func DealPoker(r io.Reader, h *Health, g PokerGame, players int, burn bool) (*PokerDeal, error) {
+	if players < 2 || players > g.MaxPlayers(burn) {
+		return nil, errors.New("not enough cards for that many players")
+	}
+
+	deck := AddDeck(1, false)
+	if err := Shuffle(r, h, deck); err != nil {
+		return nil, err
+	}
+	next := func() Card {
+		c := deck[0]
+		deck = deck[1:]
+		return c
+	}
+
+	d := &PokerDeal{Hands: make([][]Card, players)}
+	for round := 0; round < g.Hole; round++ {
+		for p := range d.Hands {
+			d.Hands[p] = append(d.Hands[p], next())
+		}
+	}
+	if g.Board {
+		for _, n := range []int{3, 1, 1} {
+			if burn {
+				d.Burned = append(d.Burned, next())
+			}
+			for i := 0; i < n; i++ {
+				d.Board = append(d.Board, next())
+			}
+		}
+	}
+	return d, nil
+}
+
+// Hand categories, weakest first.
+const (
+	HighCard = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+)
+
+var handCategoryNames = []string{
+	"High Card", "One Pair", "Two Pair", "Three of a Kind", "Straight",
+	"Flush", "Full House", "Four of a Kind", "Straight Flush",
+}
+
+// HandValue is an evaluated five-card poker hand. Ranks holds the rank
+// values (2 to 14, ace high) that break ties within the category, most
+// significant first; Cards are the five cards in the same order.
+type HandValue struct {
+	Category int
+	Ranks    []int
+	Cards    []Card
+}
+
+// Name returns the category name; an ace-high straight flush is a
+// "Royal Flush".
+func (v HandValue) Name() string {
+	if v.Category == StraightFlush && v.Ranks[0] == 14 {
+		return "Royal Flush"
+	}
+	return handCategoryNames[v.Category]
+}
+
+// Compare returns -1, 0 or 1 as v is weaker than, ties with or beats o.
+func (v HandValue) Compare(o HandValue) int {
+	if v.Category != o.Category {
+		if v.Category < o.Category {
+			return -1
+		}
+		return 1
+	}
+	return slices.Compare(v.Ranks, o.Ranks)
+}
+
+var pokerRanks = map[string]int{
+	"Two": 2, "Three": 3, "Four": 4, "Five": 5, "Six": 6, "Seven": 7, "Eight": 8,
+	"Nine": 9, "Ten": 10, "Jack": 11, "Queen": 12, "King": 13, "Ace": 14,
+}
+
+// EvaluateFive ranks a hand of exactly five standard cards.
+func EvaluateFive(cards []Card) (HandValue, error) {
+	if len(cards) != 5 {
+		return HandValue{}, errors.New("a poker hand has five cards")
+	}
+
+	counts := map[int]int{}
+	flush := true
+	for _, c := range cards {
+		rank, ok := pokerRanks[c.Value]
+		if !ok {
+			return HandValue{}, errors.New("poker hands use standard cards without jokers")
+		}
+		counts[rank]++
+		flush = flush && c.Suit == cards[0].Suit
+	}
+
+	// Order cards by rank group (larger groups first), then by rank.
+	sorted := append([]Card(nil), cards...)
+	slices.SortStableFunc(sorted, func(a, b Card) int {
+		ra, rb := pokerRanks[a.Value], pokerRanks[b.Value]
+		if counts[ra] != counts[rb] {
+			return counts[rb] - counts[ra]
+		}
+		return rb - ra
+	})
+	var ranks []int
+	for _, c := range sorted {
+		if r := pokerRanks[c.Value]; !slices.Contains(ranks, r) {
+			ranks = append(ranks, r)
+		}
+	}
+
+	straight := len(ranks) == 5 && ranks[0]-ranks[4] == 4
+	if len(ranks) == 5 && ranks[0] == 14 && ranks[1] == 5 {
+		// The wheel, A-2-3-4-5: the ace plays low.
+		straight = true
+		ranks = []int{5, 4, 3, 2, 1}
+		sorted = append(sorted[1:], sorted[0])
+	}
+
+	v := HandValue{Ranks: ranks, Cards: sorted}
+	switch first := counts[pokerRanks[sorted[0].Value]]; {
+	case straight && flush:
+		v.Category, v.Ranks = StraightFlush, ranks[:1]
+	case first == 4:
+		v.Category = FourOfAKind
+	case first == 3 && len(ranks) == 2:
+		v.Category = FullHouse
+	case flush:
+		v.Category = Flush
+	case straight:
+		v.Category, v.Ranks = Straight, ranks[:1]
+	case first == 3:
+		v.Category = ThreeOfAKind
+	case first == 2 && len(ranks) == 3:
+		v.Category = TwoPair
+	case first == 2:
+		v.Category = OnePair
+	default:
+		v.Category = HighCard
+	}
+	return v, nil
+}
+
+// combinations calls fn with every k-card subset of cards.
+func combinations(cards []Card, k int, fn func([]Card) error) error {
+	pick := make([]Card, 0, k)
+	var rec func(start int) error
+	rec = func(start int) error {
+		if len(pick) == k {
+			return fn(pick)
+		}
+		for i := start; i <= len(cards)-(k-len(pick)); i++ {
+			pick = append(pick, cards[i])
+			if err := rec(i + 1); err != nil {
+				return err
+			}
+			pick = pick[:len(pick)-1]
+		}
+		return nil
+	}
+	return rec(0)
+}
+
+// BestHand returns the best five-card hand a player can make from their hole
+// cards and the board under the game's rules.
+func (g PokerGame) BestHand(hole, board []Card) (HandValue, error) {
+	var best HandValue
+	found := false
+	consider := func(five []Card) error {
+		v, err := EvaluateFive(five)
+		if err != nil {
+			return err
+		}
+		if !found || v.Compare(best) > 0 {
+			best, found = v, true
+		}
+		return nil
+	}
+
+	if g.MustUse == 0 {
+		all := append(append([]Card(nil), hole...), board...)
+		if len(all) < 5 {
+			return HandValue{}, errors.New("a poker hand has five cards")
+		}
+		err := combinations(all, 5, func(five []Card) error {
+			return consider(append([]Card(nil), five...))
+		})
+		return best, err
+	}
+
+	err := combinations(hole, g.MustUse, func(fromHole []Card) error {
+		return combinations(board, g.BoardUse, func(fromBoard []Card) error {
+			return consider(append(append([]Card(nil), fromHole...), fromBoard...))
+		})
+	})
+	if err == nil && !found {
+		err = errors.New("not enough cards to make a hand")
+	}
+	return best, err
+}
+
+// Showdown evaluates every hand of a deal and returns the seats (0-based)
+// holding the best one; ties split.
+func (g PokerGame) Showdown(d *PokerDeal) (hands []HandValue, winners []int, err error) {
+	hands = make([]HandValue, len(d.Hands))
+	for p, hole := range d.Hands {
+		if hands[p], err = g.BestHand(hole, d.Board); err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case len(winners) == 0 || hands[p].Compare(hands[winners[0]]) > 0:
+			winners = []int{p}
+		case hands[p].Compare(hands[winners[0]]) == 0:
+			winners = append(winners, p)
+		}
+	}
+	return hands, winners, nil
+}
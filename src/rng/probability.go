@@ -0,0 +1,122 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// maxProbabilityDigits bounds the size of exact probabilities so a single
+// request can't ask for an arbitrarily large denominator.
+const maxProbabilityDigits = 1000
+
+// Probability is an exact probability Num/Den with 0 <= Num <= Den.
+// Den is kept as given (e.g. 25% stays 25/100) so rolls are reported
+// against the denominator the caller asked for.
+type Probability struct {
+	Num *big.Int
+	Den *big.Int
+}
+
+// ParsePercentProbability parses a decimal percentage with no limit on
+// decimal places ("12.5", "0.0000000001%").
+//
+// Same format and special cases as ParsePercentExact: 0 is 0/1 and 100 is 1/1.
+func ParsePercentProbability(percentStr string) (Probability, error) {
+	digits, decimals, err := parsePercentDigits(percentStr)
+	if err != nil {
+		return Probability{}, err
+	}
+	if len(digits)+decimals > maxProbabilityDigits {
+		return Probability{}, errors.New("percent precision too high")
+	}
+
+	num, _ := new(big.Int).SetString(digits, 10)
+	// den = 100 * 10^decimals
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals+2)), nil)
+
+	switch num.Cmp(den) {
+	case 1:
+		return Probability{}, errors.New("percent must not exceed 100")
+	case 0:
+		return Probability{Num: big.NewInt(1), Den: big.NewInt(1)}, nil // always pass
+	}
+	if num.Sign() == 0 {
+		return Probability{Num: big.NewInt(0), Den: big.NewInt(1)}, nil // always fail
+	}
+	return Probability{Num: num, Den: den}, nil
+}
+
+// ParseFractionProbability parses a probability between 0 and 1 written as a
+// fraction ("1/3") or a decimal ("0.25"). The fraction is reduced.
+func ParseFractionProbability(pStr string) (Probability, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(pStr), "+")
+	if s == "" {
+		return Probability{}, errors.New("probability is empty")
+	}
+	if len(s) > maxProbabilityDigits {
+		return Probability{}, errors.New("probability precision too high")
+	}
+	if strings.HasPrefix(s, "-") {
+		return Probability{}, errors.New("probability must not be negative")
+	}
+	for _, ch := range s {
+		if (ch < '0' || ch > '9') && ch != '.' && ch != '/' {
+			return Probability{}, errors.New("invalid probability format")
+		}
+	}
+
+	rat, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Probability{}, errors.New("invalid probability format")
+	}
+	if rat.Cmp(big.NewRat(1, 1)) > 0 {
+		return Probability{}, errors.New("probability must not exceed 1")
+	}
+	return Probability{Num: new(big.Int).Set(rat.Num()), Den: new(big.Int).Set(rat.Denom())}, nil
+}
+
+// ParseOddsProbability parses odds in favour written as "for:against"
+// ("1:4" is a 1 in 5 chance).
+func ParseOddsProbability(oddsStr string) (Probability, error) {
+	forStr, againstStr, ok := strings.Cut(strings.TrimSpace(oddsStr), ":")
+	if !ok {
+		return Probability{}, errors.New("odds must be written as for:against")
+	}
+
+	parse := func(s string) (*big.Int, bool) {
+		s = strings.TrimSpace(s)
+		if s == "" || len(s) > maxProbabilityDigits {
+			return nil, false
+		}
+		for _, ch := range s {
+			if ch < '0' || ch > '9' {
+				return nil, false
+			}
+		}
+		return new(big.Int).SetString(s, 10)
+	}
+
+	num, ok1 := parse(forStr)
+	against, ok2 := parse(againstStr)
+	if !ok1 || !ok2 {
+		return Probability{}, errors.New("invalid odds format")
+	}
+
+	den := new(big.Int).Add(num, against)
+	if den.Sign() == 0 {
+		return Probability{}, errors.New("odds must not both be zero")
+	}
+	return Probability{Num: num, Den: den}, nil
+}
+
+// Roll draws a uniform integer in [1, Den] and passes when it is <= Num.
+func (p Probability) Roll(r io.Reader, h *Health) (roll *big.Int, pass bool, err error) {
+	roll, err = UniformBigInt(r, h, p.Den)
+	if err != nil {
+		return nil, false, err
+	}
+	roll.Add(roll, big.NewInt(1))
+	return roll, roll.Cmp(p.Num) <= 0, nil
+}
@@ -0,0 +1,373 @@
+package rng
+
+import (
+	"errors"
+	"io"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// Regex limits.
+const (
+	MaxRegexRepeat = 100   // largest allowed bound for unbounded repetition
+	MaxRegexOutput = 10000 // runes per generated string
+)
+
+// ErrRegexTooLong is returned when a generated string would exceed
+// MaxRegexOutput runes.
+var ErrRegexTooLong = errors.New("generated string would be longer than 10000 characters")
+
+// Open-ended classes (negations, '.') are intersected with printable ASCII.
+const printableLo, printableHi = 0x20, 0x7E
+
+// Regex generates strings matching a regular expression.
+type Regex struct {
+	re        *syntax.Regexp
+	maxRepeat int
+}
+
+// ParseRegex parses a Go (RE2/Perl) regular expression for generation.
+// Unbounded repetitions (*, +, {n,}) repeat at most max(n, maxRepeat) times.
+// Anchors are accepted only at the start or end of the pattern; word
+// boundaries, backreferences and lookarounds are rejected.
+func ParseRegex(pattern string, maxRepeat int) (*Regex, error) {
+	if maxRepeat < 0 || maxRepeat > MaxRegexRepeat {
+		return nil, errors.New("max repeat must be between 0 and 100")
+	}
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		var serr *syntax.Error
+		if errors.As(err, &serr) {
+			switch {
+			case serr.Code == syntax.ErrInvalidEscape && len(serr.Expr) == 2 && serr.Expr[1] >= '1' && serr.Expr[1] <= '9':
+				return nil, errors.New("backreferences are not supported")
+			case strings.HasPrefix(serr.Expr, "(?="), strings.HasPrefix(serr.Expr, "(?!"),
+				strings.HasPrefix(serr.Expr, "(?<="), strings.HasPrefix(serr.Expr, "(?<!"):
+				return nil, errors.New("lookaround assertions are not supported")
+			}
+		}
+		return nil, errors.New("invalid regular expression: " + err.Error())
+	}
+
+	// Parse again with every alternative marked, so syntax.Parse cannot
+	// factor alternations. The first parse still reports errors against the
+	// pattern as written. Every marker must have become a group of its own;
+	// one that landed in a class or quoted text would change the pattern.
+	markedPattern, markers := markAlternatives(pattern)
+	marked, err := syntax.Parse(markedPattern, syntax.Perl)
+	if err != nil || marked.MaxCap() != re.MaxCap()+markers {
+		return nil, errors.New("regular expression uses syntax the generator cannot follow")
+	}
+	re = marked
+
+	if err := prepareRegex(re, true, true); err != nil {
+		return nil, err
+	}
+	return &Regex{re: re, maxRepeat: maxRepeat}, nil
+}
+
+// prepareRegex validates the tree and rewrites it in place for generation:
+// allowed anchors become empty matches and open-ended classes are narrowed
+// to printable ASCII. atStart/atEnd report whether the node sits at the very
+// start/end of the pattern.
+func prepareRegex(re *syntax.Regexp, atStart, atEnd bool) error {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return errors.New("regular expression can never match")
+
+	case syntax.OpBeginLine, syntax.OpBeginText:
+		if !atStart {
+			return errors.New("anchors are only supported at the start or end of the pattern")
+		}
+		re.Op = syntax.OpEmptyMatch
+
+	case syntax.OpEndLine, syntax.OpEndText:
+		if !atEnd {
+			return errors.New("anchors are only supported at the start or end of the pattern")
+		}
+		re.Op = syntax.OpEmptyMatch
+
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return errors.New("word boundaries are not supported")
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		re.Op = syntax.OpCharClass
+		re.Rune = []rune{printableLo, printableHi}
+
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 && re.Rune[len(re.Rune)-1] == unicode.MaxRune {
+			re.Rune = intersectPrintable(re.Rune)
+		}
+		if len(re.Rune) == 0 {
+			return errors.New("character class matches no printable characters")
+		}
+
+	case syntax.OpConcat:
+		for i, sub := range re.Sub {
+			start := atStart && allOps(re.Sub[:i], syntax.OpBeginLine, syntax.OpBeginText, syntax.OpEmptyMatch)
+			end := atEnd && allOps(re.Sub[i+1:], syntax.OpEndLine, syntax.OpEndText)
+			if err := prepareRegex(sub, start, end); err != nil {
+				return err
+			}
+		}
+
+	case syntax.OpAlternate, syntax.OpCapture:
+		for _, sub := range re.Sub {
+			if err := prepareRegex(sub, atStart, atEnd); err != nil {
+				return err
+			}
+		}
+		// Drop the markers left by markAlternatives so that anchors after
+		// them still count as leading.
+		if re.Op == syntax.OpCapture && re.Sub[0].Op == syntax.OpEmptyMatch {
+			re.Op, re.Sub = syntax.OpEmptyMatch, nil
+		}
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if err := prepareRegex(re.Sub[0], false, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markAlternatives puts an empty capture group at the start of the pattern,
+// of every alternative and of every group. syntax.Parse factors alternations
+// (foo|bar|baz becomes foo|ba[rz], a|b becomes [ab]), which would skew a
+// uniform choice between them; a leading group no two alternatives share
+// stops that. It also returns the number of markers inserted.
+func markAlternatives(pattern string) (string, int) {
+	var b strings.Builder
+	markers := 0
+	mark := func() {
+		b.WriteString("()")
+		markers++
+	}
+	mark()
+	for i := 0; i < len(pattern); i++ {
+		ch := pattern[i]
+		b.WriteByte(ch)
+		switch ch {
+		case '\\':
+			if i+1 >= len(pattern) {
+				continue
+			}
+			if pattern[i+1] == 'Q' {
+				// Quoted text runs to \E or the end of the pattern.
+				end := strings.Index(pattern[i+2:], `\E`)
+				if end < 0 {
+					end = len(pattern) - i - 2
+				} else {
+					end += 2
+				}
+				b.WriteString(pattern[i+1 : i+2+end])
+				i += 1 + end
+				continue
+			}
+			i++
+			b.WriteByte(pattern[i])
+
+		case '[':
+			j := i + 1
+			if j < len(pattern) && pattern[j] == '^' {
+				j++
+			}
+			if j < len(pattern) && pattern[j] == ']' {
+				j++
+			}
+			for j < len(pattern) && pattern[j] != ']' {
+				switch {
+				case pattern[j] == '\\':
+					j++
+				case strings.HasPrefix(pattern[j:], "[:"):
+					if end := strings.Index(pattern[j+2:], ":]"); end >= 0 {
+						j += end + 3
+					}
+				}
+				j++
+			}
+			end := min(j+1, len(pattern))
+			b.WriteString(pattern[i+1 : end])
+			i = end - 1
+
+		case '|':
+			mark()
+
+		case '(':
+			if i+1 >= len(pattern) || pattern[i+1] != '?' {
+				mark()
+				continue
+			}
+			// Group headers: (?flags:, (?P<name> and (?<name>. Flag groups
+			// such as (?i) and anything else are left alone.
+			j := i + 2
+			for j < len(pattern) && strings.IndexByte("imsU-", pattern[j]) >= 0 {
+				j++
+			}
+			switch {
+			case j < len(pattern) && pattern[j] == ':':
+			case strings.HasPrefix(pattern[i+2:], "P<"), strings.HasPrefix(pattern[i+2:], "<") && !strings.HasPrefix(pattern[i+2:], "<=") && !strings.HasPrefix(pattern[i+2:], "<!"):
+				end := strings.IndexByte(pattern[i:], '>')
+				if end < 0 {
+					continue
+				}
+				j = i + end
+			default:
+				continue
+			}
+			b.WriteString(pattern[i+1 : j+1])
+			mark()
+			i = j
+		}
+	}
+	return b.String(), markers
+}
+
+func allOps(subs []*syntax.Regexp, ops ...syntax.Op) bool {
+	for _, sub := range subs {
+		found := false
+		for _, op := range ops {
+			found = found || sub.Op == op
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// intersectPrintable narrows sorted [lo, hi] rune pairs to printable ASCII.
+func intersectPrintable(ranges []rune) []rune {
+	var out []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], printableLo), min(ranges[i+1], printableHi)
+		if lo <= hi {
+			out = append(out, lo, hi)
+		}
+	}
+	return out
+}
+
+// Generate returns a random string matching the expression. Alternatives,
+// repetition counts and class members are each chosen uniformly.
+func (x *Regex) Generate(r io.Reader, h *Health) (string, error) {
+	g := regexGen{r: r, h: h, maxRepeat: x.maxRepeat}
+	if err := g.gen(x.re); err != nil {
+		return "", err
+	}
+	return g.out.String(), nil
+}
+
+type regexGen struct {
+	r         io.Reader
+	h         *Health
+	maxRepeat int
+	out       strings.Builder
+	n         int
+}
+
+func (g *regexGen) emit(ch rune) error {
+	if g.n++; g.n > MaxRegexOutput {
+		return ErrRegexTooLong
+	}
+	g.out.WriteRune(ch)
+	return nil
+}
+
+func (g *regexGen) gen(re *syntax.Regexp) error {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return nil
+
+	case syntax.OpLiteral:
+		for _, ch := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				var err error
+				if ch, err = g.foldCase(ch); err != nil {
+					return err
+				}
+			}
+			if err := g.emit(ch); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case syntax.OpCharClass:
+		total := 0
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			total += int(re.Rune[i+1]-re.Rune[i]) + 1
+		}
+		idx, err := UniformIndex(g.r, g.h, total)
+		if err != nil {
+			return err
+		}
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			size := int(re.Rune[i+1]-re.Rune[i]) + 1
+			if idx < size {
+				return g.emit(re.Rune[i] + rune(idx))
+			}
+			idx -= size
+		}
+		return nil
+
+	case syntax.OpCapture:
+		return g.gen(re.Sub[0])
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := g.gen(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case syntax.OpAlternate:
+		idx, err := UniformIndex(g.r, g.h, len(re.Sub))
+		if err != nil {
+			return err
+		}
+		return g.gen(re.Sub[idx])
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			lo, hi = 0, -1
+		case syntax.OpPlus:
+			lo, hi = 1, -1
+		case syntax.OpQuest:
+			lo, hi = 0, 1
+		}
+		if hi < 0 {
+			hi = max(lo, g.maxRepeat)
+		}
+		n, err := UniformIndex(g.r, g.h, hi-lo+1)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < lo+n; i++ {
+			if err := g.gen(re.Sub[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errors.New("unsupported regular expression construct")
+}
+
+// foldCase picks uniformly among the case variants of ch (e.g. k, K and the
+// Kelvin sign).
+func (g *regexGen) foldCase(ch rune) (rune, error) {
+	variants := []rune{ch}
+	for f := unicode.SimpleFold(ch); f != ch; f = unicode.SimpleFold(f) {
+		variants = append(variants, f)
+	}
+	idx, err := UniformIndex(g.r, g.h, len(variants))
+	if err != nil {
+		return 0, err
+	}
+	return variants[idx], nil
+}
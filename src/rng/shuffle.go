@@ -0,0 +1,65 @@
+package rng
+
+import (
+	"errors"
+	"io"
+)
+
+// Shuffle permutes items in place with a Fisher–Yates shuffle.
+// Every swap index comes from UniformIndex, so all n! orderings are equally likely.
+func Shuffle[T any](r io.Reader, h *Health, items []T) error {
+	for i := len(items) - 1; i > 0; i-- {
+		j, err := UniformIndex(r, h, i+1)
+		if err != nil {
+			return err
+		}
+		items[i], items[j] = items[j], items[i]
+	}
+	return nil
+}
+
+// PartialShuffle returns the first k entries of a uniformly random
+// permutation of [0, n): a Fisher–Yates shuffle stopped after k steps over an
+// implicit identity array. Only displaced positions are stored, so it takes
+// k random indices and O(k) time and memory however large n is.
+func PartialShuffle(r io.Reader, h *Health, n int, k int) ([]int, error) {
+	if k < 0 || k > n {
+		return nil, errors.New("count must be between 0 and n")
+	}
+
+	moved := make(map[int]int, k)
+	at := func(i int) int {
+		if v, ok := moved[i]; ok {
+			return v
+		}
+		return i
+	}
+
+	out := make([]int, k)
+	for i := 0; i < k; i++ {
+		j, err := UniformIndex(r, h, n-i)
+		if err != nil {
+			return nil, err
+		}
+		j += i
+		out[i], moved[j] = at(j), at(i)
+		delete(moved, i) // position i is never read again
+	}
+	return out, nil
+}
+
+// Sequence returns the integers in [min, max] in a uniformly random order.
+func Sequence(r io.Reader, h *Health, min int, max int) ([]int, error) {
+	if min > max {
+		return nil, errors.New("min must be less than or equal to max")
+	}
+
+	seq := make([]int, 0, max-min+1)
+	for v := min; v <= max; v++ {
+		seq = append(seq, v)
+	}
+	if err := Shuffle(r, h, seq); err != nil {
+		return nil, err
+	}
+	return seq, nil
+}
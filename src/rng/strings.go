@@ -1,5 +1,13 @@
 package rng
 
+import (
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"unicode"
+)
+
 func BuildCharset(lowers, uppers, numbers, symbols bool) []byte {
 	var b []byte
 	if lowers {
@@ -16,3 +24,226 @@ func BuildCharset(lowers, uppers, numbers, symbols bool) []byte {
 	}
 	return b
 }
+
+// AmbiguousChars are characters easily confused with one another when read
+// or typed (0/O, 1/l/I).
+const AmbiguousChars = "0O1lI"
+
+// Character classes used for per-class minimums, in StringSampler order.
+const (
+	ClassLower = iota
+	ClassUpper
+	ClassDigit
+	ClassSymbol
+	ClassOther // runes outside the four classes above (e.g. CJK)
+	NumClasses
+)
+
+// ClassOf returns the character class of a rune.
+func ClassOf(ch rune) int {
+	switch {
+	case unicode.IsLower(ch):
+		return ClassLower
+	case unicode.IsUpper(ch):
+		return ClassUpper
+	case unicode.IsDigit(ch):
+		return ClassDigit
+	case unicode.IsPunct(ch) || unicode.IsSymbol(ch):
+		return ClassSymbol
+	}
+	return ClassOther
+}
+
+// FilterCharset removes duplicates and every rune in exclude from charset,
+// keeping the original order.
+func FilterCharset(charset []rune, exclude string) []rune {
+	seen := make(map[rune]bool, len(charset))
+	for _, ch := range exclude {
+		seen[ch] = true
+	}
+
+	out := make([]rune, 0, len(charset))
+	for _, ch := range charset {
+		if !seen[ch] {
+			seen[ch] = true
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// StringSampler draws strings of a fixed length from a charset, uniformly
+// over all strings that contain at least Min[class] runes of each class.
+//
+// There is no rejection of whole strings: it first samples how many runes of
+// each class the string gets (weighted by the exact number of strings with
+// that composition), then a uniformly random arrangement of those classes,
+// then a uniform rune per position. Every valid string is equally likely.
+type StringSampler struct {
+	length  int
+	charset []rune
+	classes [NumClasses][]rune
+	min     [NumClasses]int
+
+	// ways[j][m] = number of length-m strings over classes j.. that satisfy
+	// the minimums of classes j..; nil when there are no minimums.
+	ways  [][]*big.Int
+	binom [][]*big.Int
+}
+
+// NewStringSampler validates the policy and precomputes the counting tables.
+func NewStringSampler(charset []rune, length int, min [NumClasses]int) (*StringSampler, error) {
+	if len(charset) == 0 {
+		return nil, errors.New("charset is empty")
+	}
+	if length < 1 {
+		return nil, errors.New("length must be at least 1")
+	}
+
+	s := &StringSampler{length: length, charset: charset, min: min}
+	for _, ch := range charset {
+		c := ClassOf(ch)
+		s.classes[c] = append(s.classes[c], ch)
+	}
+
+	required := 0
+	for c := 0; c < NumClasses; c++ {
+		if min[c] < 0 {
+			return nil, errors.New("minimums must not be negative")
+		}
+		if min[c] > 0 && len(s.classes[c]) == 0 {
+			return nil, errors.New("a minimum is set for a class with no characters in the charset")
+		}
+		required += min[c]
+	}
+	if required > length {
+		return nil, errors.New("minimums add up to more than the length")
+	}
+	if required > 0 {
+		s.ways = s.countWays()
+	}
+	return s, nil
+}
+
+func (s *StringSampler) countWays() [][]*big.Int {
+	n := s.length
+	s.binom = binomialRows(n)
+	binom := s.binom
+
+	ways := make([][]*big.Int, NumClasses+1)
+	ways[NumClasses] = make([]*big.Int, n+1)
+	for m := range ways[NumClasses] {
+		ways[NumClasses][m] = big.NewInt(0)
+	}
+	ways[NumClasses][0].SetInt64(1)
+
+	for j := NumClasses - 1; j >= 0; j-- {
+		size := big.NewInt(int64(len(s.classes[j])))
+		ways[j] = make([]*big.Int, n+1)
+		for m := 0; m <= n; m++ {
+			total := new(big.Int)
+			pow := new(big.Int).Exp(size, big.NewInt(int64(s.min[j])), nil)
+			term := new(big.Int)
+			for k := s.min[j]; k <= m; k++ {
+				// C(m,k) * size^k * ways[j+1][m-k]
+				term.Mul(binom[m][k], pow)
+				term.Mul(term, ways[j+1][m-k])
+				total.Add(total, term)
+				pow.Mul(pow, size)
+			}
+			ways[j][m] = total
+		}
+	}
+	return ways
+}
+
+// binomialRows returns Pascal's triangle up to row n.
+func binomialRows(n int) [][]*big.Int {
+	rows := make([][]*big.Int, n+1)
+	for m := 0; m <= n; m++ {
+		rows[m] = make([]*big.Int, m+1)
+		rows[m][0], rows[m][m] = big.NewInt(1), big.NewInt(1)
+		for k := 1; k < m; k++ {
+			rows[m][k] = new(big.Int).Add(rows[m-1][k-1], rows[m-1][k])
+		}
+	}
+	return rows
+}
+
+// EntropyBits returns log2 of the number of strings the sampler can produce.
+func (s *StringSampler) EntropyBits() float64 {
+	if s.ways == nil {
+		return float64(s.length) * math.Log2(float64(len(s.charset)))
+	}
+	return log2Big(s.ways[0][s.length])
+}
+
+func log2Big(x *big.Int) float64 {
+	// Keep the top 64 bits exact in a float and add the shifted-out bits.
+	shift := x.BitLen() - 64
+	if shift < 0 {
+		shift = 0
+	}
+	top, _ := new(big.Float).SetInt(new(big.Int).Rsh(x, uint(shift))).Float64()
+	return math.Log2(top) + float64(shift)
+}
+
+// Generate draws one string.
+func (s *StringSampler) Generate(r io.Reader, h *Health) (string, error) {
+	out := make([]rune, s.length)
+
+	if s.ways == nil {
+		for i := range out {
+			idx, err := UniformIndex(r, h, len(s.charset))
+			if err != nil {
+				return "", err
+			}
+			out[i] = s.charset[idx]
+		}
+		return string(out), nil
+	}
+
+	// 1. Class composition, weighted by the number of strings it yields.
+	binom := s.binom
+	labels := make([]int, 0, s.length)
+	m := s.length
+	for j := 0; j < NumClasses; j++ {
+		x, err := UniformBigInt(r, h, s.ways[j][m])
+		if err != nil {
+			return "", err
+		}
+
+		size := big.NewInt(int64(len(s.classes[j])))
+		pow := new(big.Int).Exp(size, big.NewInt(int64(s.min[j])), nil)
+		term := new(big.Int)
+		k := s.min[j]
+		for ; k <= m; k++ {
+			term.Mul(binom[m][k], pow)
+			term.Mul(term, s.ways[j+1][m-k])
+			if x.Cmp(term) < 0 {
+				break
+			}
+			x.Sub(x, term)
+			pow.Mul(pow, size)
+		}
+		for i := 0; i < k; i++ {
+			labels = append(labels, j)
+		}
+		m -= k
+	}
+
+	// 2. Uniform arrangement of the classes.
+	if err := Shuffle(r, h, labels); err != nil {
+		return "", err
+	}
+
+	// 3. Uniform rune within each position's class.
+	for i, c := range labels {
+		idx, err := UniformIndex(r, h, len(s.classes[c]))
+		if err != nil {
+			return "", err
+		}
+		out[i] = s.classes[c][idx]
+	}
+	return string(out), nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"math/big"
 )
 
 // UniformInt32 returns a uniform integer in [min, max] inclusive.
@@ -47,3 +48,86 @@ func UniformInt32(r io.Reader, h *Health, min int, max int) (int32, error) {
 		// reject and retry
 	}
 }
+
+// UniformIndex returns a uniform index in [0, n).
+// A single-element range consumes no entropy.
+func UniformIndex(r io.Reader, h *Health, n int) (int, error) {
+	if n < 1 {
+		return 0, errors.New("n must be at least 1")
+	}
+	if n == 1 {
+		return 0, nil
+	}
+	index, err := UniformInt32(r, h, 0, n-1)
+	return int(index), err
+}
+
+// UniformBigInt returns a uniform integer in [0, n) for any n >= 1.
+//
+// Ranges that fit in 32 bits use the same rejection sampling as UniformInt32
+// (and consume the stream identically). Larger ranges read just enough bytes
+// for n's bit length, mask the excess top bits and reject values >= n, which
+// accepts more than half of all draws.
+func UniformBigInt(r io.Reader, h *Health, n *big.Int) (*big.Int, error) {
+	if n.Sign() <= 0 {
+		return nil, errors.New("n must be at least 1")
+	}
+
+	if n.BitLen() <= 32 {
+		rangeSize := n.Uint64()
+		limit := (uint64(1) << 32) / rangeSize * rangeSize
+
+		var buf [4]byte
+		for {
+			if err := readFull(r, h, buf[:]); err != nil {
+				return nil, err
+			}
+			x := uint64(binary.BigEndian.Uint32(buf[:]))
+			if x < limit {
+				return new(big.Int).SetUint64(x % rangeSize), nil
+			}
+		}
+	}
+
+	bits := n.BitLen()
+	buf := make([]byte, (bits+7)/8)
+	excess := uint(len(buf)*8 - bits)
+	x := new(big.Int)
+	for {
+		if err := readFull(r, h, buf); err != nil {
+			return nil, err
+		}
+		buf[0] &= 0xff >> excess
+		if x.SetBytes(buf).Cmp(n) < 0 {
+			return x, nil
+		}
+	}
+}
+
+// UniformInt64 returns a uniform integer in [min, max] inclusive,
+// covering the full int64 range via UniformBigInt.
+func UniformInt64(r io.Reader, h *Health, min int64, max int64) (int64, error) {
+	if min > max {
+		return 0, errors.New("min must be less than or equal to max")
+	}
+
+	n := new(big.Int).Sub(big.NewInt(max), big.NewInt(min))
+	n.Add(n, big.NewInt(1))
+	v, err := UniformBigInt(r, h, n)
+	if err != nil {
+		return 0, err
+	}
+	// Wrapping uint64 addition lands back inside [min, max].
+	return int64(uint64(min) + v.Uint64()), nil
+}
+
+// readFull fills buf from r, marking the RNG unhealthy on failure.
+func readFull(r io.Reader, h *Health, buf []byte) error {
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if h != nil {
+			h.Set(false, "error fetching random bytes: "+err.Error())
+		}
+		return errors.New("error fetching random bytes")
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"time"
 )
 
 // NewUUIDv4FromRNG generates an RFC4122 UUID v4 using the same RNG stream.
@@ -17,10 +18,32 @@ func NewUUIDv4FromRNG(r io.Reader) (string, error) {
 	// Set version (4) and variant (10xx)
 	b[6] = (b[6] & 0x0f) | 0x40
 	b[8] = (b[8] & 0x3f) | 0x80
+	return formatUUID(b), nil
+}
+
+// NewUUIDv7 generates an RFC 9562 UUID v7: a 48-bit Unix millisecond
+// timestamp followed by 74 random bits, so IDs sort by creation time.
+func NewUUIDv7(r io.Reader, h *Health, now time.Time) (string, error) {
+	var b [16]byte
+	if err := readFull(r, h, b[6:]); err != nil {
+		return "", err
+	}
+
+	ms := uint64(now.UnixMilli())
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+
+	// Set version (7) and variant (10xx)
+	b[6] = (b[6] & 0x0f) | 0x70
+	b[8] = (b[8] & 0x3f) | 0x80
+	return formatUUID(b), nil
+}
 
+func formatUUID(b [16]byte) string {
 	hex32 := make([]byte, 32)
 	hex.Encode(hex32, b[:])
 	return fmt.Sprintf("%s-%s-%s-%s-%s",
 		hex32[0:8], hex32[8:12], hex32[12:16], hex32[16:20], hex32[20:32],
-	), nil
+	)
 }
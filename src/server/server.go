@@ -34,8 +34,8 @@ func New(port string, r io.Reader, h *rng.Health, log *zap.SugaredLogger) *Serve
 	go rng.PeriodicHealthCheck(r, h, interval)
 
 	router.Use(cors.New(cors.Config{
-		AllowMethods:     []string{"GET"},
-		AllowHeaders:     []string{"X-API-KEY", "Accept"},
+		AllowMethods:     []string{"GET", "POST", "DELETE"},
+		AllowHeaders:     []string{"X-API-KEY", "Accept", "Content-Type"},
 		AllowAllOrigins:  true,
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
@@ -46,8 +46,43 @@ func New(port string, r io.Reader, h *rng.Health, log *zap.SugaredLogger) *Serve
 	router.GET("/", handlers.RandomNumber)
 	router.GET("/bytes", handlers.RandomBytes)
 	router.GET("/cards", handlers.RandomCards)
+	router.POST("/cards", handlers.RandomCards)
+	router.POST("/decks", handlers.CreateDeck)
+	router.GET("/decks/:id", handlers.GetDeck)
+	router.DELETE("/decks/:id", handlers.DeleteDeck)
+	router.POST("/decks/:id/draw", handlers.DrawDeck)
+	router.GET("/decks/:id/peek", handlers.PeekDeck)
+	router.POST("/decks/:id/burn", handlers.BurnDeck)
+	router.POST("/decks/:id/return", handlers.ReturnDeck)
+	router.POST("/decks/:id/shuffle", handlers.ShuffleDeck)
+	router.GET("/poker/deal", handlers.PokerDeal)
 	router.GET("/strings", handlers.RandomStrings)
+	router.GET("/passphrase", handlers.RandomPassphrase)
+	router.GET("/pattern", handlers.RandomPattern)
+	router.GET("/regex", handlers.RandomRegex)
+	router.GET("/id", handlers.RandomID)
+	router.GET("/keys", api.RequireHeader("X-API-KEY", api.APIKeyFromEnv()), handlers.RandomKeys)
+	router.GET("/mnemonic", api.RequireHeader("X-API-KEY", api.APIKeyFromEnv()), handlers.RandomMnemonic)
+	router.POST("/mnemonic", api.RequireHeader("X-API-KEY", api.APIKeyFromEnv()), handlers.RandomMnemonic)
+	router.GET("/otp/secret", api.RequireHeader("X-API-KEY", api.APIKeyFromEnv()), handlers.OTPSecret)
+	router.POST("/otp/verify", handlers.OTPVerify)
+	router.GET("/net/mac", handlers.RandomMAC)
+	router.GET("/net/ip", handlers.RandomIP)
+	router.GET("/net/port", handlers.RandomPort)
+	router.GET("/stream", handlers.Stream)
 	router.GET("/percent", handlers.RandomPercent)
+	router.GET("/outcome", handlers.RandomOutcome)
+	router.GET("/choice", handlers.RandomChoice)
+	router.GET("/shuffle", handlers.RandomShuffle)
+	router.POST("/shuffle", handlers.RandomShuffle)
+	router.GET("/sequence", handlers.RandomSequence)
+	router.GET("/lottery", handlers.RandomLottery)
+	router.GET("/dice", handlers.RandomDice)
+	router.GET("/datetime", handlers.RandomDateTime)
+	router.GET("/duration", handlers.RandomDuration)
+	router.GET("/geo", handlers.RandomGeo)
+	router.POST("/geo", handlers.RandomGeo)
+	router.GET("/point", handlers.RandomPoint)
 	router.GET("/health", handlers.Health)
 
 	return &Server{port: port, router: router}
@@ -2,7 +2,10 @@ package api_test
 
 import (
 	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"strings"
 	"testing"
@@ -97,3 +100,537 @@ func extractJSONField(body string, field string) string {
 	}
 	return body[start : start+end]
 }
+
+func TestHandlers_BatchCountSharesOneRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	// JSON batch
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/?min=1&max=6&count=5", nil)
+	c.Request.Header.Set("Accept", "application/json")
+	h.RandomNumber(c)
+
+	if w.Code != 200 {
+		t.Fatalf("json expected 200 got %d: %s", w.Code, w.Body.String())
+	}
+	body := w.Body.String()
+	if strings.Count(body, "request_id") != 1 || !strings.Contains(body, `"results":[`) {
+		t.Fatalf("unexpected batch body: %s", body)
+	}
+	if strings.Contains(body, `"number"`) {
+		t.Fatalf("batch body should not contain the single-value field: %s", body)
+	}
+
+	// Text batch: count lines plus the request_id line
+	w2 := httptest.NewRecorder()
+	c2, _ := gin.CreateTestContext(w2)
+	c2.Request = httptest.NewRequest("GET", "/strings?size=8&count=3", nil)
+	h.RandomStrings(c2)
+
+	if w2.Code != 200 {
+		t.Fatalf("text expected 200 got %d: %s", w2.Code, w2.Body.String())
+	}
+	if lines := strings.Split(w2.Body.String(), "\n"); len(lines) != 4 {
+		t.Fatalf("expected 3 values and a request_id line, got %q", lines)
+	}
+
+	// Out-of-range count
+	w3 := httptest.NewRecorder()
+	c3, _ := gin.CreateTestContext(w3)
+	c3.Request = httptest.NewRequest("GET", "/bytes?count=0", nil)
+	h.RandomBytes(c3)
+
+	if w3.Code != 400 {
+		t.Fatalf("count=0 expected 400 got %d", w3.Code)
+	}
+}
+
+func TestRequireHeader_RejectsWhenUnconfigured(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	tests := []struct {
+		configured string
+		sent       string
+		want       int
+	}{
+		{"", "", 403},
+		{"", "anything", 403},
+		{"secret", "wrong", 403},
+		{"secret", "secret", 200},
+	}
+	for _, tt := range tests {
+		router := gin.New()
+		router.GET("/keys", api.RequireHeader("X-API-KEY", tt.configured), h.RandomKeys)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/keys?type=ed25519", nil)
+		req.Header.Set("X-API-KEY", tt.sent)
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Fatalf("configured %q sent %q: expected %d got %d", tt.configured, tt.sent, tt.want, w.Code)
+		}
+		if tt.want == 200 {
+			if got := w.Header().Get("Cache-Control"); got != "no-store" {
+				t.Fatalf("expected Cache-Control no-store, got %q", got)
+			}
+			if !strings.Contains(w.Body.String(), "BEGIN PRIVATE KEY") {
+				t.Fatalf("unexpected body: %s", w.Body.String())
+			}
+		}
+	}
+}
+
+func TestRandomBytes_EncodingsAndBinaryStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	lengths := map[string]int{"hex": 20, "base64": 16, "base64url": 14, "base32": 16, "bits": 80}
+	for encoding, want := range lengths {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/bytes?size=10&encoding="+encoding, nil)
+		h.RandomBytes(c)
+
+		if w.Code != 200 {
+			t.Fatalf("%s: expected 200 got %d: %s", encoding, w.Code, w.Body.String())
+		}
+		value := strings.SplitN(w.Body.String(), "\n", 2)[0]
+		if len(value) != want {
+			t.Fatalf("%s: got %q (len %d) want len %d", encoding, value, len(value), want)
+		}
+	}
+
+	// Binary: raw body, request id in the trailer.
+	const size = 100_000
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/bytes?size=100000&encoding=binary", nil)
+	h.RandomBytes(c)
+
+	res := w.Result()
+	if res.StatusCode != 200 || res.Header.Get("Content-Type") != "application/octet-stream" {
+		t.Fatalf("binary: unexpected status %d / content type %q", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	if w.Body.Len() != size {
+		t.Fatalf("binary: got %d bytes want %d", w.Body.Len(), size)
+	}
+	if id := res.Trailer.Get("X-Request-Id"); !uuidV4Re.MatchString(id) {
+		t.Fatalf("binary: bad X-Request-Id trailer %q", id)
+	}
+
+	// Limits.
+	for _, query := range []string{
+		"/bytes?size=10&encoding=binary&count=2",
+		"/bytes?size=1048577",
+		"/bytes?size=1048576&count=2",
+		"/bytes?size=16777217&encoding=binary",
+		"/bytes?encoding=octal",
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", query, nil)
+		h.RandomBytes(c)
+		if w.Code != 400 {
+			t.Fatalf("%s: expected 400 got %d", query, w.Code)
+		}
+	}
+}
+
+func TestRandomCards_DeckTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	cases := []struct {
+		method, url, body string
+		status            int
+	}{
+		{"GET", "/cards?type=uno&cards=108", "", 200},
+		{"GET", "/cards?type=uno&cards=109", "", 400},
+		{"GET", "/cards?type=tarot&cards=3", "", 200},
+		{"GET", "/cards?type=pinochle&jokers=true", "", 400},
+		{"GET", "/cards?type=bridge", "", 400},
+		{"POST", "/cards?type=custom&cards=4", `{"suits":["Red","Blue"],"values":["1","2"]}`, 200},
+		{"POST", "/cards?type=custom", `{"suits":["Red"]}`, 400},
+		{"GET", "/cards?format=code&cards=5", "", 200},
+		{"GET", "/cards?type=mahjong&format=glyph", "", 200},
+		{"GET", "/cards?type=uno&format=glyph", "", 400},
+		{"GET", "/cards?format=emoji", "", 400},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+		h.RandomCards(c)
+
+		if w.Code != tc.status {
+			t.Fatalf("%s %s: expected %d got %d: %s", tc.method, tc.url, tc.status, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/cards?type=custom&cards=4", strings.NewReader(
+		`{"suits":["Red","Blue"],"values":["1","2"],"template":"{suit} {value}"}`))
+	h.RandomCards(c)
+	lines := strings.Split(w.Body.String(), "\n")
+	seen := map[string]bool{}
+	for _, l := range lines[:4] {
+		seen[l] = true
+	}
+	for _, want := range []string{"Red 1", "Red 2", "Blue 1", "Blue 2"} {
+		if !seen[want] {
+			t.Fatalf("custom deck draw %q is missing %q", lines, want)
+		}
+	}
+}
+
+func TestRandomCards_CodeFormat(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/cards?format=code&cards=52", nil)
+	h.RandomCards(c)
+
+	codeRe := regexp.MustCompile(`^(A|[2-9]|10|J|Q|K)[HDCS]$`)
+	lines := strings.Split(w.Body.String(), "\n")
+	seen := map[string]bool{}
+	for _, l := range lines[:52] {
+		if !codeRe.MatchString(l) || seen[l] {
+			t.Fatalf("bad or repeated code %q", l)
+		}
+		seen[l] = true
+	}
+}
+
+func TestPokerDeal_EvaluatesWinners(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/poker/deal?game=omaha&players=4&evaluate=true&format=code", nil)
+	c.Request.Header.Set("Accept", "application/json")
+	h.PokerDeal(c)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Players []struct {
+			Seat int                       `json:"seat"`
+			Hole []any                     `json:"hole"`
+			Hand struct{ Category string } `json:"hand"`
+		} `json:"players"`
+		Board   []any `json:"board"`
+		Burned  []any `json:"burned"`
+		Winners []int `json:"winners"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Players) != 4 || len(body.Board) != 5 || len(body.Burned) != 3 || len(body.Winners) == 0 {
+		t.Fatalf("unexpected deal: %s", w.Body.String())
+	}
+	for _, p := range body.Players {
+		if len(p.Hole) != 4 || p.Hand.Category == "" {
+			t.Fatalf("seat %d: %s", p.Seat, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/poker/deal?game=holdem&players=24", nil)
+	h.PokerDeal(c)
+	if w.Code != 400 {
+		t.Fatalf("24 players: expected 400 got %d", w.Code)
+	}
+}
+
+func TestRandomMnemonic_PassphraseFromBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/mnemonic", strings.NewReader(`{"passphrase":"TREZOR"}`))
+	c.Request.Header.Set("Accept", "application/json")
+	h.RandomMnemonic(c)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200 got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Mnemonic string `json:"mnemonic"`
+		Seed     string `json:"seed"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if want := hex.EncodeToString(rng.MnemonicSeed(body.Mnemonic, "TREZOR")); body.Seed != want {
+		t.Fatalf("seed %q, want %q", body.Seed, want)
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/mnemonic?passphrase=TREZOR", nil)
+	h.RandomMnemonic(c)
+	if w.Code != 400 {
+		t.Fatalf("query passphrase: expected 400 got %d", w.Code)
+	}
+}
+
+func TestOTPVerify_SecretFromBodyWithoutRNG(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Verification must not depend on the RNG.
+	health := rng.NewHealth()
+	health.Set(false, "device unplugged")
+	h := api.NewHandlers(nil, health, zap.NewNop().Sugar())
+
+	key, _ := rng.DecodeOTPSecret("JBSWY3DPEHPK3PXP")
+	code, err := rng.OTPCode(key, "SHA1", 6, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/otp/verify?type=hotp&counter=5",
+		strings.NewReader(`{"secret":"JBSWY3DPEHPK3PXP","code":"`+code+`"}`))
+	c.Request.Header.Set("Accept", "application/json")
+	h.OTPVerify(c)
+
+	if w.Code != 200 || extractJSONField(w.Body.String(), "request_id") != "otp-verify" ||
+		!strings.Contains(w.Body.String(), `"valid":true`) {
+		t.Fatalf("unexpected response %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/otp/verify?secret=JBSWY3DPEHPK3PXP", strings.NewReader(`{"code":"123456"}`))
+	h.OTPVerify(c)
+	if w.Code != 400 {
+		t.Fatalf("query secret: expected 400 got %d", w.Code)
+	}
+}
+
+func TestGeoAndPoint_RejectNonFiniteFloats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	tests := []struct {
+		handler func(*gin.Context)
+		url     string
+	}{
+		{h.RandomGeo, "/geo?mode=bbox&bbox=0,0,NaN,10"},
+		{h.RandomGeo, "/geo?mode=bbox&bbox=-Inf,0,10,10"},
+		{h.RandomGeo, "/geo?mode=radius&lat=NaN&lon=0&radius=100"},
+		{h.RandomGeo, "/geo?mode=radius&lat=0&lon=0&radius=Inf"},
+		{h.RandomPoint, "/point?shape=sphere&radius=Inf"},
+		{h.RandomPoint, "/point?shape=ball&radius=NaN"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", tt.url, nil)
+		tt.handler(c)
+		if w.Code != 400 {
+			t.Fatalf("%s: expected 400 got %d: %s", tt.url, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHandlers_BatchCountForListEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	tests := []struct {
+		handler func(*gin.Context)
+		url     string
+	}{
+		{h.RandomDice, "/dice?expr=4d6kh3%2B2&count=3"},
+		{h.RandomLottery, "/lottery?preset=powerball&count=3"},
+		{h.RandomChoice, "/choice?items=a,b,c&k=2&count=3"},
+		{h.RandomSequence, "/sequence?min=1&max=5&count=3"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", tt.url, nil)
+		tt.handler(c)
+		if w.Code != 200 {
+			t.Fatalf("%s: expected 200 got %d: %s", tt.url, w.Code, w.Body.String())
+		}
+		if lines := strings.Split(w.Body.String(), "\n"); len(lines) != 4 {
+			t.Fatalf("%s: expected 3 results and a request_id line, got %q", tt.url, lines)
+		}
+
+		w = httptest.NewRecorder()
+		c, _ = gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", tt.url, nil)
+		c.Request.Header.Set("Accept", "application/json")
+		tt.handler(c)
+		var body struct {
+			Count   int   `json:"count"`
+			Results []any `json:"results"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Count != 3 || len(body.Results) != 3 {
+			t.Fatalf("%s: unexpected batch body: %s", tt.url, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/sequence?min=1&max=10000&count=11", nil)
+	h.RandomSequence(c)
+	if w.Code != 400 {
+		t.Fatalf("oversized sequence batch: expected 400 got %d", w.Code)
+	}
+}
+
+func TestDateTimeAndDuration_ReadErrorsAre500(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	health := rng.NewHealth()
+	tests := []struct {
+		handler func(*api.Handlers, *gin.Context)
+		url     string
+		want    int
+	}{
+		{(*api.Handlers).RandomDateTime, "/datetime", 500},
+		{(*api.Handlers).RandomDuration, "/duration", 500},
+		{(*api.Handlers).RandomDateTime, "/datetime?start=2024-02-01T00:00:00Z&end=2024-01-01T00:00:00Z", 400},
+		{(*api.Handlers).RandomDuration, "/duration?min=10s&max=50s&granularity=minute", 400},
+		// A weekend-only range is rejected before any entropy is read.
+		{(*api.Handlers).RandomDateTime, "/datetime?start=2024-06-08T00:00:00Z&end=2024-06-09T23:00:00Z&weekdays=true", 400},
+	}
+	for _, tt := range tests {
+		health.Set(true, "")
+		// An empty reader fails every read.
+		h := api.NewHandlers(strings.NewReader(""), health, zap.NewNop().Sugar())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", tt.url, nil)
+		tt.handler(h, c)
+		if w.Code != tt.want {
+			t.Fatalf("%s: expected %d got %d: %s", tt.url, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRandomGeo_ReadErrorsAre500(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	health := rng.NewHealth()
+	tests := []struct {
+		url  string
+		want int
+	}{
+		{"/geo?mode=sphere", 500},
+		{"/geo?mode=bbox&bbox=0,0,10,10", 500},
+		{"/geo?mode=radius&lat=0&lon=0&radius=100", 500},
+		{"/geo?mode=polygon&polygon=" + url.QueryEscape(`{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`), 500},
+		{"/geo?mode=bbox&bbox=10,0,0,10", 400},
+		{"/geo?mode=radius&lat=0&lon=0&radius=-5", 400},
+		{"/geo?mode=polygon&polygon=" + url.QueryEscape(`{"type":"Polygon","coordinates":[[[0,0],[1,1],[1,0],[0,1],[0,0]]]}`), 400},
+	}
+	for _, tt := range tests {
+		health.Set(true, "")
+		// An empty reader fails every read.
+		h := api.NewHandlers(strings.NewReader(""), health, zap.NewNop().Sugar())
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", tt.url, nil)
+		h.RandomGeo(c)
+		if w.Code != tt.want {
+			t.Fatalf("%s: expected %d got %d: %s", tt.url, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestDecks_StateAndDeleteWithoutRNG(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	rr := &uint32CounterReader{next: 1}
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rr, health, zap.NewNop().Sugar())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/decks?type=standard", nil)
+	c.Request.Header.Set("Accept", "application/json")
+	h.CreateDeck(c)
+	var created struct {
+		Deck rng.DeckState `json:"deck"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || created.Deck.ID == "" {
+		t.Fatalf("create: unexpected body %s", w.Body.String())
+	}
+
+	// Reading and deleting a deck take no entropy, so they still work while
+	// the RNG is unhealthy.
+	health.Set(false, "device unplugged")
+	for _, tt := range []struct {
+		handler func(*gin.Context)
+		method  string
+		want    int
+	}{
+		{h.GetDeck, "GET", 200},
+		{h.DeleteDeck, "DELETE", 200},
+		{h.GetDeck, "GET", 404},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(tt.method, "/decks/"+created.Deck.ID, nil)
+		c.Params = gin.Params{{Key: "id", Value: created.Deck.ID}}
+		tt.handler(c)
+		if w.Code != tt.want {
+			t.Fatalf("%s: expected %d got %d: %s", tt.method, tt.want, w.Code, w.Body.String())
+		}
+	}
+}
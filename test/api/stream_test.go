@@ -0,0 +1,135 @@
+package api_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+
+	"github.com/lost-woods/random/src/api"
+	"github.com/lost-woods/random/src/rng"
+)
+
+func newStreamServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	health := rng.NewHealth()
+	health.Set(true, "")
+	h := api.NewHandlers(rng.NewLockedReader(&uint32CounterReader{next: 1}), health, zap.NewNop().Sugar())
+
+	router := gin.New()
+	router.GET("/stream", h.Stream)
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStream_SSEEventsHaveOwnIDs(t *testing.T) {
+	srv := newStreamServer(t)
+
+	res, err := http.Get(srv.URL + "/stream?type=number&min=1&max=6&rate=20&limit=3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != 200 || !strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("unexpected response %d %q", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+
+	ids := map[string]bool{}
+	events := 0
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "id:"):
+			id := strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+			if !uuidV4Re.MatchString(id) || ids[id] {
+				t.Fatalf("bad or repeated event id %q", id)
+			}
+			ids[id] = true
+		case strings.HasPrefix(line, "event:"):
+			if strings.TrimSpace(strings.TrimPrefix(line, "event:")) != "number" {
+				t.Fatalf("unexpected event line %q", line)
+			}
+			events++
+		}
+	}
+	if events != 3 || len(ids) != 3 {
+		t.Fatalf("expected 3 events with ids, got %d events / %d ids", events, len(ids))
+	}
+}
+
+func TestStream_WebSocket(t *testing.T) {
+	srv := newStreamServer(t)
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/stream?type=bytes&size=4&rate=20&limit=2"
+	ws, err := websocket.Dial(url, "", srv.URL)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer ws.Close()
+
+	for i := 1; i <= 2; i++ {
+		var ev struct {
+			ID    string `json:"id"`
+			Seq   int    `json:"seq"`
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		}
+		if err := websocket.JSON.Receive(ws, &ev); err != nil {
+			t.Fatalf("receive %d: %v", i, err)
+		}
+		if ev.Seq != i || ev.Type != "bytes" || len(ev.Value) != 8 || !uuidV4Re.MatchString(ev.ID) {
+			t.Fatalf("unexpected event %+v", ev)
+		}
+	}
+}
+
+func TestStream_RejectsBadSpecs(t *testing.T) {
+	srv := newStreamServer(t)
+
+	for _, query := range []string{
+		"type=dice", "rate=0", "rate=100", "type=bytes&size=1000", "limit=-1",
+		"min=-2000000000", "max=2000000000",
+	} {
+		res, err := http.Get(srv.URL + "/stream?" + query)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		res.Body.Close()
+		if res.StatusCode != 400 {
+			t.Fatalf("%s: expected 400 got %d", query, res.StatusCode)
+		}
+	}
+}
+
+func TestStream_SubscriberLimit(t *testing.T) {
+	t.Setenv("MAX_STREAM_SUBSCRIBERS", "1")
+	srv := newStreamServer(t)
+
+	first, err := http.Get(srv.URL + "/stream?rate=1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer first.Body.Close()
+	// Wait for the first event so the subscriber slot is certainly taken.
+	if _, err := bufio.NewReader(first.Body).ReadString('\n'); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := http.Get(srv.URL + "/stream?rate=1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second.Body.Close()
+	if second.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 got %d", second.StatusCode)
+	}
+}
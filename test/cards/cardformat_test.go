@@ -0,0 +1,213 @@
+package cards_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestCardFormat_StandardCodesAndGlyphs(t *testing.T) {
+	def, _ := rng.LookupDeckType("standard")
+	cases := []struct {
+		card         rng.Card
+		code, glyph  string
+		rank, suitIx int
+	}{
+		{rng.Card{Value: "Ace", Suit: "Spades"}, "AS", "\U0001F0A1", 1, 4},
+		{rng.Card{Value: "Ten", Suit: "Hearts"}, "10H", "\U0001F0BA", 10, 1},
+		{rng.Card{Value: "Queen", Suit: "Hearts"}, "QH", "\U0001F0BD", 12, 1},
+		{rng.Card{Value: "King", Suit: "Clubs"}, "KC", "\U0001F0DE", 13, 3},
+		{rng.Card{Value: "Joker", Suit: "Red"}, "RJ", "\U0001F0BF", 14, 5},
+	}
+	for _, tc := range cases {
+		if got := def.FormatCard(tc.card, rng.FormatCode); got != tc.code {
+			t.Fatalf("%+v code: got %q want %q", tc.card, got, tc.code)
+		}
+		if got := def.FormatCard(tc.card, rng.FormatGlyph); got != tc.glyph {
+			t.Fatalf("%+v glyph: got %q want %q", tc.card, got, tc.glyph)
+		}
+		if rank, suit := def.Indices(tc.card); rank != tc.rank || suit != tc.suitIx {
+			t.Fatalf("%+v indices: got %d:%d want %d:%d", tc.card, rank, suit, tc.rank, tc.suitIx)
+		}
+	}
+}
+
+func TestCardFormat_OtherGlyphs(t *testing.T) {
+	cases := []struct {
+		deck  string
+		card  rng.Card
+		glyph string
+	}{
+		{"mahjong", rng.Card{Value: "1", Suit: "Dots"}, "\U0001F019"},
+		{"mahjong", rng.Card{Value: "9", Suit: "Characters"}, "\U0001F00F"},
+		{"mahjong", rng.Card{Value: "East", Suit: "Wind"}, "\U0001F000"},
+		{"mahjong", rng.Card{Value: "Winter", Suit: "Season"}, "\U0001F029"},
+		{"dominoes", rng.Card{Value: "0", Suit: "0"}, "\U0001F031"},
+		{"dominoes", rng.Card{Value: "5", Suit: "3"}, "\U0001F04B"},
+		{"dominoes", rng.Card{Value: "6", Suit: "6"}, "\U0001F061"},
+	}
+	for _, tc := range cases {
+		def, _ := rng.LookupDeckType(tc.deck)
+		if got := def.FormatCard(tc.card, rng.FormatGlyph); got != tc.glyph {
+			t.Fatalf("%s %+v: got %U want %U", tc.deck, tc.card, []rune(got), []rune(tc.glyph))
+		}
+	}
+
+	for _, name := range []string{"tarot", "uno", "spanish", "italian"} {
+		def, _ := rng.LookupDeckType(name)
+		if def.SupportsFormat(rng.FormatGlyph) {
+			t.Fatalf("%s should not support glyphs", name)
+		}
+	}
+}
+
+func TestCardFormat_RoundTripAllTypes(t *testing.T) {
+	for _, name := range rng.DeckTypeNames() {
+		def, _ := rng.LookupDeckType(name)
+		for _, format := range rng.CardFormats {
+			if !def.SupportsFormat(format) {
+				continue
+			}
+			seen := map[string]rng.Card{}
+			for _, c := range def.Build(1, true) {
+				s := def.FormatCard(c, format)
+				if s == "" {
+					t.Fatalf("%s/%s: %+v has no rendering", name, format, c)
+				}
+				if prev, ok := seen[s]; ok && prev != c {
+					t.Fatalf("%s/%s: %q renders both %+v and %+v", name, format, s, prev, c)
+				}
+				seen[s] = c
+
+				parsed, err := def.ParseCards([]string{s})
+				if err != nil || parsed[0] != c {
+					t.Fatalf("%s/%s: %q parsed to %+v, %v; want %+v", name, format, s, parsed, err, c)
+				}
+			}
+		}
+		if !def.SupportsFormat(rng.FormatCode) {
+			t.Fatalf("%s has no codes", name)
+		}
+	}
+}
+
+func TestCardFormat_ParseReversedAndJSON(t *testing.T) {
+	def, _ := rng.LookupDeckType("tarot")
+	cards, err := def.ParseCards([]string{"qcr", "M0", "The Tower (reversed)", "1:2r"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []rng.Card{
+		{Value: "Queen", Suit: "Cups", Reversed: true},
+		{Value: "The Fool"},
+		{Value: "The Tower", Reversed: true},
+		{Value: "Ace", Suit: "Cups", Reversed: true},
+	}
+	for i, w := range want {
+		if cards[i] != w {
+			t.Fatalf("card %d: got %+v want %+v", i, cards[i], w)
+		}
+	}
+
+	std, _ := rng.LookupDeckType("standard")
+	if _, err := std.ParseCards([]string{"ASr"}); err == nil {
+		t.Fatal("standard cards cannot be reversed")
+	}
+	got, err := std.DecodeCards([]byte(`["as", "10h", {"value": "Two", "suit": "Clubs"}, "` + "\U0001F0DE" + `"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStd := []rng.Card{{Value: "Ace", Suit: "Spades"}, {Value: "Ten", Suit: "Hearts"}, {Value: "Two", Suit: "Clubs"}, {Value: "King", Suit: "Clubs"}}
+	for i := range wantStd {
+		if got[i] != wantStd[i] {
+			t.Fatalf("card %d: got %+v want %+v", i, got[i], wantStd[i])
+		}
+	}
+	for _, bad := range []string{`[]`, `{}`, `["ZZ"]`, `[{"suit": "Clubs"}]`} {
+		if _, err := std.DecodeCards([]byte(bad)); err == nil {
+			t.Fatalf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestCardFormat_CustomCodes(t *testing.T) {
+	def, err := rng.ParseDeckType([]byte(`{
+		"suits": ["Red", "Blue"], "suit_codes": ["R", "B"],
+		"values": ["One", "Two"], "value_codes": ["1", "2"],
+		"extra": [{"value": "Bomb", "code": "X"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := def.FormatCard(rng.Card{Value: "Two", Suit: "Blue"}, rng.FormatCode); got != "2B" {
+		t.Fatalf("code %q", got)
+	}
+
+	bad := []string{
+		`{"suits": ["A"], "suit_codes": ["a", "b"], "values": ["1"], "value_codes": ["1"]}`,
+		`{"suits": ["A", "B"], "suit_codes": ["x", "X"], "values": ["1"], "value_codes": ["1"]}`,
+		`{"extra": [{"value": "A", "code": "has space"}]}`,
+	}
+	for _, b := range bad {
+		if _, err := rng.ParseDeckType([]byte(b)); err == nil {
+			t.Fatalf("expected error for %s", b)
+		}
+	}
+}
+
+func TestCardFormat_OversizedCodedDefinitionRejectedCheaply(t *testing.T) {
+	names := make([]string, 3000)
+	for i := range names {
+		names[i] = "n" + strconv.Itoa(i)
+	}
+	def, _ := json.Marshal(map[string]any{
+		"suits": names, "suit_codes": names,
+		"values": names, "value_codes": names,
+		"copies": 100,
+	})
+
+	start := time.Now()
+	_, err := rng.ParseDeckType(def)
+	if err == nil || !strings.Contains(err.Error(), "at most") {
+		t.Fatalf("expected a size error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("rejecting an oversized definition took %v", elapsed)
+	}
+}
+
+func TestCardFormat_LargeCustomDeckParsesQuickly(t *testing.T) {
+	extra := make([]map[string]any, 5000)
+	names := make([]string, len(extra))
+	for i := range extra {
+		names[i] = "c" + strconv.Itoa(i)
+		extra[i] = map[string]any{"value": "V" + strconv.Itoa(i), "code": names[i]}
+	}
+	def, _ := json.Marshal(map[string]any{"extra": extra})
+
+	start := time.Now()
+	dt, err := rng.ParseDeckType(def)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cards, err := dt.ParseCards(names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cards {
+		if rank, _ := dt.Indices(c); rank == 0 {
+			t.Fatalf("no index for %v", c)
+		}
+		dt.FormatCard(c, rng.FormatIndex)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("parsing and indexing %d cards took %v", len(cards), elapsed)
+	}
+	if got := dt.FormatCard(cards[4999], rng.FormatCode); got != "c4999" {
+		t.Fatalf("expected c4999, got %q", got)
+	}
+}
@@ -0,0 +1,158 @@
+package cards_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func standardConfig() rng.DeckConfig {
+	def, _ := rng.LookupDeckType("standard")
+	return rng.DeckConfig{Type: "standard", Decks: 1, Definition: def}
+}
+
+func newDeck(t *testing.T, s *rng.DeckStore) rng.DeckState {
+	t.Helper()
+	state, err := s.Create(rand.Reader, nil, standardConfig())
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return state
+}
+
+func TestDeckStore_DrawPeekBurnReturnShuffle(t *testing.T) {
+	s := rng.NewDeckStore(time.Hour, 10)
+	id := newDeck(t, s).ID
+
+	peeked, _, err := s.Peek(id, 3)
+	if err != nil {
+		t.Fatalf("peek: %v", err)
+	}
+	drawn, state, err := s.Draw(id, 3)
+	if err != nil {
+		t.Fatalf("draw: %v", err)
+	}
+	for i := range drawn {
+		if drawn[i] != peeked[i] {
+			t.Fatalf("draw %v does not match peek %v", drawn, peeked)
+		}
+	}
+	if state.Remaining != 49 || state.Dealt != 3 {
+		t.Fatalf("after draw: %+v", state)
+	}
+
+	if _, state, err = s.Burn(id, 2); err != nil || state.Remaining != 47 || state.Discarded != 2 {
+		t.Fatalf("after burn: %+v, %v", state, err)
+	}
+
+	if _, err := s.Return(id, []rng.Card{{Value: "Joker", Suit: "Joker"}}, false); !errors.Is(err, rng.ErrCardNotDealt) {
+		t.Fatalf("returning an undealt card: got %v", err)
+	}
+	if state, err = s.Return(id, drawn[:1], false); err != nil || state.Dealt != 2 || state.Discarded != 3 {
+		t.Fatalf("after return: %+v, %v", state, err)
+	}
+	if _, err := s.Return(id, drawn[:1], false); !errors.Is(err, rng.ErrCardNotDealt) {
+		t.Fatalf("returning a card twice: got %v", err)
+	}
+
+	if state, err = s.Shuffle(rand.Reader, nil, id); err != nil || state.Remaining != 50 || state.Discarded != 0 || state.Dealt != 2 {
+		t.Fatalf("after shuffle: %+v, %v", state, err)
+	}
+	if state, err = s.Return(id, nil, true); err != nil || state.Dealt != 0 || state.Discarded != 2 {
+		t.Fatalf("after return all: %+v, %v", state, err)
+	}
+
+	if _, _, err := s.Draw(id, 51); !errors.Is(err, rng.ErrNotEnoughCards) {
+		t.Fatalf("overdraw: got %v", err)
+	}
+
+	// Every card of the deck is accounted for exactly once.
+	rest, _, err := s.Draw(id, 50)
+	if err != nil {
+		t.Fatalf("draw rest: %v", err)
+	}
+	if _, err := s.Shuffle(rand.Reader, nil, id); err != nil {
+		t.Fatalf("shuffle: %v", err)
+	}
+	more, _, err := s.Draw(id, 2)
+	if err != nil {
+		t.Fatalf("draw discards: %v", err)
+	}
+	seen := map[rng.Card]bool{}
+	for _, c := range append(rest, more...) {
+		if seen[c] {
+			t.Fatalf("duplicate card %v", c)
+		}
+		seen[c] = true
+	}
+	if len(seen) != 52 {
+		t.Fatalf("saw %d distinct cards, want 52", len(seen))
+	}
+
+	if err := s.Delete(id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := s.State(id); !errors.Is(err, rng.ErrDeckNotFound) {
+		t.Fatalf("state after delete: got %v", err)
+	}
+}
+
+func TestDeckStore_ExpiryAndLimit(t *testing.T) {
+	s := rng.NewDeckStore(20*time.Millisecond, 1)
+	id := newDeck(t, s).ID
+
+	// A full store refuses before reading any entropy, so even a failing
+	// reader reports the limit.
+	if _, err := s.Create(strings.NewReader(""), nil, standardConfig()); !errors.Is(err, rng.ErrTooManyDecks) {
+		t.Fatalf("create over limit: got %v", err)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if _, err := s.State(id); !errors.Is(err, rng.ErrDeckNotFound) {
+		t.Fatalf("expired deck: got %v", err)
+	}
+	// A failed shuffle gives its reserved slot back.
+	if _, err := s.Create(strings.NewReader(""), nil, standardConfig()); err == nil || errors.Is(err, rng.ErrTooManyDecks) {
+		t.Fatalf("create with failing reader: got %v", err)
+	}
+	// The expired deck no longer counts against the limit.
+	newDeck(t, s)
+}
+
+func TestDeckStore_ConcurrentDraws(t *testing.T) {
+	s := rng.NewDeckStore(time.Hour, 10)
+	id := newDeck(t, s).ID
+
+	var mu sync.Mutex
+	seen := map[rng.Card]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 26; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cards, _, err := s.Draw(id, 2)
+			if err != nil {
+				t.Errorf("draw: %v", err)
+				return
+			}
+			mu.Lock()
+			for _, c := range cards {
+				seen[c]++
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != 52 {
+		t.Fatalf("concurrent draws dealt %d distinct cards, want 52", len(seen))
+	}
+	if state, _ := s.State(id); state.Remaining != 0 || state.Dealt != 52 {
+		t.Fatalf("after concurrent draws: %+v", state)
+	}
+}
@@ -0,0 +1,154 @@
+package cards_test
+
+import (
+	"crypto/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestDeckTypes_Sizes(t *testing.T) {
+	want := map[string]int{
+		"standard": 52,
+		"piquet":   32,
+		"euchre":   24,
+		"pinochle": 48,
+		"spanish":  40,
+		"italian":  40,
+		"tarot":    78,
+		"uno":      108,
+		"mahjong":  144,
+		"dominoes": 28,
+	}
+	if names := rng.DeckTypeNames(); len(names) != len(want) {
+		t.Fatalf("deck types %v, want %d", names, len(want))
+	}
+
+	for name, size := range want {
+		def, ok := rng.LookupDeckType(name)
+		if !ok {
+			t.Fatalf("missing deck type %q", name)
+		}
+		if err := def.Validate(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		deck := def.Build(2, false)
+		if len(deck) != 2*size || def.Size(false) != size {
+			t.Fatalf("%s: built %d cards for 2 decks, size %d, want %d per deck", name, len(deck), def.Size(false), size)
+		}
+	}
+}
+
+func TestDeckTypes_StandardMatchesAddDeck(t *testing.T) {
+	def, _ := rng.LookupDeckType("standard")
+	if !reflect.DeepEqual(def.Build(3, true), rng.AddDeck(3, true)) {
+		t.Fatal("standard deck type differs from AddDeck")
+	}
+}
+
+func TestDeckTypes_CardNames(t *testing.T) {
+	cases := []struct {
+		deck string
+		card rng.Card
+		want string
+	}{
+		{"standard", rng.Card{Value: "Ace", Suit: "Spades"}, "Ace of Spades"},
+		{"standard", rng.Card{Value: "Joker", Suit: "Red"}, "Joker of Red"},
+		{"tarot", rng.Card{Value: "The Tower", Reversed: true}, "The Tower (reversed)"},
+		{"tarot", rng.Card{Value: "Page", Suit: "Cups"}, "Page of Cups"},
+		{"uno", rng.Card{Value: "Draw Two", Suit: "Green"}, "Green Draw Two"},
+		{"uno", rng.Card{Value: "Wild"}, "Wild"},
+		{"spanish", rng.Card{Value: "Caballo", Suit: "Copas"}, "Caballo de Copas"},
+		{"mahjong", rng.Card{Value: "East", Suit: "Wind"}, "East Wind"},
+		{"dominoes", rng.Card{Value: "5", Suit: "3"}, "3|5"},
+	}
+	for _, tc := range cases {
+		def, _ := rng.LookupDeckType(tc.deck)
+		if got := def.CardName(tc.card); got != tc.want {
+			t.Fatalf("%s %+v: got %q, want %q", tc.deck, tc.card, got, tc.want)
+		}
+	}
+}
+
+func TestDeckTypes_ParseCustom(t *testing.T) {
+	def, err := rng.ParseDeckType([]byte(`{
+		"suits": ["Red", "Blue"],
+		"values": ["1", "2", "3"],
+		"copies": 2,
+		"extra": [{"value": "Bomb", "count": 3}],
+		"template": "{suit} {value}"
+	}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if def.Name != "custom" || def.Size(false) != 15 {
+		t.Fatalf("got %+v with %d cards", def, def.Size(false))
+	}
+	if got := def.CardName(rng.Card{Value: "2", Suit: "Blue"}); got != "Blue 2" {
+		t.Fatalf("card name %q", got)
+	}
+
+	bad := []string{
+		``,
+		`[]`,
+		`{}`,
+		`{"suits": ["A"]}`,
+		`{"suits": ["A"], "values": [""]}`,
+		`{"extra": [{"value": "X", "count": -1}]}`,
+		`{"suits": ["A"], "values": ["B"], "copies": 101}`,
+		`{"suits": ["A"], "values": ["B"], "colour": "red"}`,
+	}
+	for _, b := range bad {
+		if _, err := rng.ParseDeckType([]byte(b)); err == nil {
+			t.Fatalf("expected error for %q", b)
+		}
+	}
+}
+
+func TestOrient_Balanced(t *testing.T) {
+	cards := make([]rng.Card, 10000)
+	if err := rng.Orient(rand.Reader, nil, cards); err != nil {
+		t.Fatal(err)
+	}
+	reversed := 0
+	for _, c := range cards {
+		if c.Reversed {
+			reversed++
+		}
+	}
+	// 10000 fair coin flips: mean 5000, sd 50.
+	if reversed < 4750 || reversed > 5250 {
+		t.Fatalf("%d of %d cards reversed", reversed, len(cards))
+	}
+}
+
+func TestDeckStore_Reversals(t *testing.T) {
+	def, _ := rng.LookupDeckType("tarot")
+	s := rng.NewDeckStore(time.Hour, 1)
+	state, err := s.Create(rand.Reader, nil, rng.DeckConfig{Type: "tarot", Decks: 1, Reversals: true, Definition: def})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cards, _, err := s.Draw(state.ID, 78)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reversed := 0
+	for _, c := range cards {
+		if c.Reversed {
+			reversed++
+		}
+	}
+	if reversed == 0 || reversed == 78 {
+		t.Fatalf("%d of 78 cards reversed", reversed)
+	}
+
+	// Orientation is ignored when returning cards.
+	upright := cards[0]
+	upright.Reversed = false
+	if _, err := s.Return(state.ID, []rng.Card{upright}, false); err != nil {
+		t.Fatalf("return: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package cards_test
+
+import (
+	"crypto/rand"
+	"io"
+	mrand "math/rand/v2"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestDrawCards_FullShoeKeepsMultiplicity(t *testing.T) {
+	const decks = 100
+	deck := rng.AddDeck(1, true)
+	drawn, err := rng.DrawCards(rand.Reader, nil, deck, decks, len(deck)*decks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[rng.Card]int{}
+	for _, c := range drawn {
+		counts[c]++
+	}
+	if len(counts) != len(deck) {
+		t.Fatalf("drew %d distinct cards, want %d", len(counts), len(deck))
+	}
+	for c, n := range counts {
+		if n != decks {
+			t.Fatalf("%v drawn %d times, want %d", c, n, decks)
+		}
+	}
+
+	if _, err := rng.DrawCards(rand.Reader, nil, deck, 1, len(deck)+1); err == nil {
+		t.Fatal("expected error when drawing more cards than the shoe holds")
+	}
+}
+
+// benchReader is a fast deterministic source so the benchmarks measure the
+// draw algorithm rather than the entropy source.
+func benchReader() io.Reader {
+	return mrand.NewChaCha8([32]byte{1})
+}
+
+// removeDraw is the previous approach: build the whole shoe and remove each
+// pick with an O(n) slice shift.
+func removeDraw(r io.Reader, deck []rng.Card, numDecks, k int) ([]rng.Card, error) {
+	shoe := make([]rng.Card, 0, len(deck)*numDecks)
+	for i := 0; i < numDecks; i++ {
+		shoe = append(shoe, deck...)
+	}
+	picked := make([]rng.Card, 0, k)
+	for i := 0; i < k; i++ {
+		j, err := rng.UniformIndex(r, nil, len(shoe))
+		if err != nil {
+			return nil, err
+		}
+		picked = append(picked, shoe[j])
+		shoe = append(shoe[:j], shoe[j+1:]...)
+	}
+	return picked, nil
+}
+
+func benchmarkDraw(b *testing.B, numDecks, k int, draw func(io.Reader, []rng.Card, int, int) ([]rng.Card, error)) {
+	r := benchReader()
+	deck := rng.AddDeck(1, true)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := draw(r, deck, numDecks, k); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func partialDraw(r io.Reader, deck []rng.Card, numDecks, k int) ([]rng.Card, error) {
+	return rng.DrawCards(r, nil, deck, numDecks, k)
+}
+
+func BenchmarkDrawCards_Full100Decks(b *testing.B)  { benchmarkDraw(b, 100, 5400, partialDraw) }
+func BenchmarkDrawCards_Five100Decks(b *testing.B)  { benchmarkDraw(b, 100, 5, partialDraw) }
+func BenchmarkDrawCards_FullOneDeck(b *testing.B)   { benchmarkDraw(b, 1, 54, partialDraw) }
+func BenchmarkRemoveDraw_Full100Decks(b *testing.B) { benchmarkDraw(b, 100, 5400, removeDraw) }
+func BenchmarkRemoveDraw_Five100Decks(b *testing.B) { benchmarkDraw(b, 100, 5, removeDraw) }
@@ -0,0 +1,167 @@
+package cards_test
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func parseCards(t *testing.T, codes string) []rng.Card {
+	t.Helper()
+	def, _ := rng.LookupDeckType("standard")
+	cards, err := def.ParseCards(strings.Fields(codes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cards
+}
+
+func TestPoker_EvaluateFiveCategories(t *testing.T) {
+	cases := []struct {
+		hand string
+		want string
+	}{
+		{"AS KS QS JS 10S", "Royal Flush"},
+		{"5H 4H 3H 2H AH", "Straight Flush"},
+		{"9C 9D 9H 9S 2C", "Four of a Kind"},
+		{"3C 3D 3H KS KC", "Full House"},
+		{"2D 7D 9D JD KD", "Flush"},
+		{"AC 2D 3H 4S 5C", "Straight"},
+		{"10C JD QH KS AC", "Straight"},
+		{"7C 7D 7H 2S 9C", "Three of a Kind"},
+		{"7C 7D 2H 2S 9C", "Two Pair"},
+		{"7C 7D 3H 2S 9C", "One Pair"},
+		{"7C 8D 3H 2S KC", "High Card"},
+		{"QC KD AH 2S 3C", "High Card"}, // no wrap-around straight
+	}
+	for _, tc := range cases {
+		v, err := rng.EvaluateFive(parseCards(t, tc.hand))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.Name() != tc.want {
+			t.Fatalf("%s: got %s want %s", tc.hand, v.Name(), tc.want)
+		}
+	}
+
+	if _, err := rng.EvaluateFive(parseCards(t, "RJ 2C 3C 4C 5C")); err == nil {
+		t.Fatal("jokers should be rejected")
+	}
+}
+
+func TestPoker_CompareOrdersHands(t *testing.T) {
+	// Each hand beats the one before it.
+	ordered := []string{
+		"7C 5D 4H 3S 2C",
+		"8C 5D 4H 3S 2C",
+		"2C 2D 5H 4S 3C",
+		"2C 2D AH KS QC",
+		"3C 3D 4H 5S 6C",
+		"3C 3D 2H 2S AC",
+		"3C 3D 4H 4S 2C",
+		"2C 2D 2H 4S 5C",
+		"AC 2D 3H 4S 5C",
+		"2C 3D 4H 5S 6C",
+		"10C JD QH KS AC",
+		"2D 3D 4D 5D 7D",
+		"2D 3D 4D 5D 8D",
+		"2C 2D 2H 3S 3C",
+		"3C 3D 3H 2S 2C",
+		"2C 2D 2H 2S 3C",
+		"2C 2D 2H 2S 4C",
+		"AH 2H 3H 4H 5H",
+		"9H 10H JH QH KH",
+		"10H JH QH KH AH",
+	}
+	prev, _ := rng.EvaluateFive(parseCards(t, ordered[0]))
+	for _, hand := range ordered[1:] {
+		v, _ := rng.EvaluateFive(parseCards(t, hand))
+		if v.Compare(prev) <= 0 || prev.Compare(v) >= 0 {
+			t.Fatalf("%s (%s) should beat the previous hand (%s)", hand, v.Name(), prev.Name())
+		}
+		prev = v
+	}
+
+	a, _ := rng.EvaluateFive(parseCards(t, "AC KD QH JS 9C"))
+	b, _ := rng.EvaluateFive(parseCards(t, "AD KH QS JC 9D"))
+	if a.Compare(b) != 0 {
+		t.Fatal("suits must not break ties")
+	}
+}
+
+func TestPoker_BestHandRules(t *testing.T) {
+	board := parseCards(t, "2H 7H 9H KH 3C")
+	holdem, _ := rng.LookupPokerGame("holdem")
+	omaha, _ := rng.LookupPokerGame("omaha")
+
+	// Hold'em may use one hole card: a flush.
+	v, err := holdem.BestHand(parseCards(t, "AH 4S"), board)
+	if err != nil || v.Name() != "Flush" {
+		t.Fatalf("holdem: got %s, %v", v.Name(), err)
+	}
+	// Omaha must use exactly two hole cards: one heart is not enough.
+	v, err = omaha.BestHand(parseCards(t, "AH 4S 5D 8C"), board)
+	if err != nil || v.Name() == "Flush" {
+		t.Fatalf("omaha: got %s, %v", v.Name(), err)
+	}
+	v, err = omaha.BestHand(parseCards(t, "AH QH 5D 8C"), board)
+	if err != nil || v.Name() != "Flush" || v.Ranks[0] != 14 {
+		t.Fatalf("omaha flush: got %s %v, %v", v.Name(), v.Ranks, err)
+	}
+
+	// Board plays: both hold'em players split.
+	deal := &rng.PokerDeal{
+		Hands: [][]rng.Card{parseCards(t, "2C 3D"), parseCards(t, "2D 3S")},
+		Board: parseCards(t, "10C JD QH KS AC"),
+	}
+	_, winners, err := holdem.Showdown(deal)
+	if err != nil || len(winners) != 2 {
+		t.Fatalf("split pot: winners %v, %v", winners, err)
+	}
+}
+
+func TestPoker_DealUsesOneDeck(t *testing.T) {
+	for _, game := range []string{"holdem", "omaha", "draw"} {
+		g, _ := rng.LookupPokerGame(game)
+		players := g.MaxPlayers(true)
+		deal, err := rng.DealPoker(rand.Reader, nil, g, players, true)
+		if err != nil {
+			t.Fatalf("%s: %v", game, err)
+		}
+
+		seen := map[rng.Card]bool{}
+		add := func(cards []rng.Card) {
+			for _, c := range cards {
+				if seen[c] {
+					t.Fatalf("%s: %v dealt twice", game, c)
+				}
+				seen[c] = true
+			}
+		}
+		for _, hole := range deal.Hands {
+			if len(hole) != g.Hole {
+				t.Fatalf("%s: %d hole cards", game, len(hole))
+			}
+			add(hole)
+		}
+		add(deal.Board)
+		add(deal.Burned)
+
+		wantBoard, wantBurned := 0, 0
+		if g.Board {
+			wantBoard, wantBurned = 5, 3
+		}
+		if len(deal.Board) != wantBoard || len(deal.Burned) != wantBurned {
+			t.Fatalf("%s: board %d burned %d", game, len(deal.Board), len(deal.Burned))
+		}
+
+		if _, _, err := g.Showdown(deal); err != nil {
+			t.Fatalf("%s showdown: %v", game, err)
+		}
+		if _, err := rng.DealPoker(rand.Reader, nil, g, players+1, true); err == nil {
+			t.Fatalf("%s: dealt to %d players", game, players+1)
+		}
+	}
+}
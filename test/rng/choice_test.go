@@ -0,0 +1,97 @@
+package rng_test
+
+import (
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestParseWeights(t *testing.T) {
+	tests := []struct {
+		in      []string
+		want    []int
+		wantErr bool
+	}{
+		{[]string{"1", "2", "3"}, []int{1, 2, 3}, false},
+		{[]string{"2.5", "1"}, []int{25, 10}, false},
+		{[]string{"0.125", "0.5", "+1"}, []int{125, 500, 1000}, false},
+		{[]string{"0", "4.000"}, []int{0, 4}, false},
+
+		{[]string{}, nil, true},
+		{[]string{"0", "0"}, nil, true},
+		{[]string{"-1", "2"}, nil, true},
+		{[]string{"abc"}, nil, true},
+		{[]string{"1..2"}, nil, true},
+		{[]string{"1000000001"}, nil, true},
+		{[]string{"600000000", "600000000"}, nil, true},
+		{[]string{"0.0000000001"}, nil, true}, // >9 decimals
+	}
+
+	for _, tc := range tests {
+		got, err := rng.ParseWeights(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("input=%q expected error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("input=%q unexpected error: %v", tc.in, err)
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("input=%q got %v want %v", tc.in, got, tc.want)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("input=%q got %v want %v", tc.in, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestWeightedChoice_ExactProportionsOverFullCycle(t *testing.T) {
+	// Total weight 8 divides 2^32, so a counter stream hits every roll equally often.
+	weights := []int{1, 0, 3, 4}
+	r := &uint32CounterReader{next: 0}
+
+	counts := make([]int, len(weights))
+	for i := 0; i < 8000; i++ {
+		picked, err := rng.WeightedChoice(r, nil, weights, 1, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts[picked[0]]++
+	}
+
+	for i, w := range weights {
+		if counts[i] != w*1000 {
+			t.Fatalf("index %d count=%d want=%d", i, counts[i], w*1000)
+		}
+	}
+}
+
+func TestWeightedChoice_WithoutReplacementIsDistinct(t *testing.T) {
+	weights := []int{5, 1, 0, 2, 7}
+	r := &xorshift32{x: 0xC0FFEE}
+
+	for i := 0; i < 1000; i++ {
+		picked, err := rng.WeightedChoice(r, nil, weights, 4, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen := map[int]bool{}
+		for _, idx := range picked {
+			if weights[idx] == 0 {
+				t.Fatalf("picked zero-weight item %d", idx)
+			}
+			if seen[idx] {
+				t.Fatalf("duplicate pick %d in %v", idx, picked)
+			}
+			seen[idx] = true
+		}
+	}
+
+	if _, err := rng.WeightedChoice(r, nil, weights, 5, false); err == nil {
+		t.Fatalf("expected error when k exceeds positive-weight items")
+	}
+}
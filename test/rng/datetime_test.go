@@ -0,0 +1,204 @@
+package rng_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestUniformInt64_FullRangeAndBounds(t *testing.T) {
+	r := &xorshift32{x: 0x600D}
+	cases := [][2]int64{
+		{0, 0},
+		{-5, 5},
+		{1 << 40, 1<<40 + 3},
+		{-1 << 63, 1<<63 - 1},
+	}
+	for _, tc := range cases {
+		for i := 0; i < 1000; i++ {
+			v, err := rng.UniformInt64(r, nil, tc[0], tc[1])
+			if err != nil {
+				t.Fatalf("range=%v unexpected error: %v", tc, err)
+			}
+			if v < tc[0] || v > tc[1] {
+				t.Fatalf("range=%v got out-of-range %d", tc, v)
+			}
+		}
+	}
+}
+
+func TestRandomDateTime_GranularityAndBounds(t *testing.T) {
+	r := &xorshift32{x: 0xDA7E}
+	start := time.Date(2024, 3, 1, 10, 30, 0, 500, time.UTC)
+	end := time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)
+
+	for _, g := range []string{"day", "minute", "second", "millisecond"} {
+		for i := 0; i < 500; i++ {
+			got, err := rng.RandomDateTime(r, nil, rng.DateTimeSpec{Start: start, End: end, Granularity: g})
+			if err != nil {
+				t.Fatalf("granularity=%s unexpected error: %v", g, err)
+			}
+			if got.Before(start) || got.After(end) {
+				t.Fatalf("granularity=%s got %s outside range", g, got)
+			}
+			unit := map[string]time.Duration{
+				"day": 24 * time.Hour, "minute": time.Minute,
+				"second": time.Second, "millisecond": time.Millisecond,
+			}[g]
+			if got.UnixNano()%int64(unit) != 0 {
+				t.Fatalf("granularity=%s got %s not a whole unit", g, got)
+			}
+		}
+	}
+}
+
+func TestRandomDateTime_FiltersInTimeZone(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	r := &xorshift32{x: 0xB12}
+	spec := rng.DateTimeSpec{
+		Start:         time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:           time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+		Granularity:   "second",
+		Location:      loc,
+		Weekdays:      true,
+		BusinessHours: true,
+	}
+	for i := 0; i < 500; i++ {
+		got, err := rng.RandomDateTime(r, nil, spec)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Location() != loc {
+			t.Fatalf("got location %s", got.Location())
+		}
+		if wd := got.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			t.Fatalf("got weekend %s", got)
+		}
+		if got.Hour() < 9 || got.Hour() >= 17 {
+			t.Fatalf("got outside business hours %s", got)
+		}
+	}
+
+	// A weekend-only range can never match the weekday filter.
+	spec.Start = time.Date(2024, 6, 8, 0, 0, 0, 0, loc)
+	spec.End = time.Date(2024, 6, 9, 23, 0, 0, 0, loc)
+	if _, err := rng.RandomDateTime(r, nil, spec); !errors.Is(err, rng.ErrNoMatchingInstant) {
+		t.Fatalf("expected ErrNoMatchingInstant for a weekend-only range, got %v", err)
+	}
+}
+
+func TestRandomDateTime_FiltersSampleExactly(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	r := &xorshift32{x: 0xE8AC}
+
+	// One allowed second among more than 200,000 never makes a draw fail.
+	only := time.Date(2024, 6, 7, 16, 59, 59, 0, loc) // a Friday
+	s, err := rng.NewDateTimeSampler(rng.DateTimeSpec{
+		Start:         only,
+		End:           time.Date(2024, 6, 10, 8, 59, 59, 0, loc),
+		Granularity:   "second",
+		Location:      loc,
+		Weekdays:      true,
+		BusinessHours: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		got, err := s.Sample(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !got.Equal(only) {
+			t.Fatalf("got %s want %s", got, only)
+		}
+	}
+
+	// Monday 16:00 to Tuesday 10:00 allows 60 minutes on Monday and 61 on
+	// Tuesday; each day must get its share.
+	s, err = rng.NewDateTimeSampler(rng.DateTimeSpec{
+		Start:         time.Date(2024, 6, 10, 16, 0, 0, 0, loc),
+		End:           time.Date(2024, 6, 11, 10, 0, 0, 0, loc),
+		Granularity:   "minute",
+		Location:      loc,
+		BusinessHours: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	const draws = 24200
+	counts := make([]int, 2)
+	for i := 0; i < draws; i++ {
+		got, err := s.Sample(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Hour() < 9 || got.Hour() >= 17 {
+			t.Fatalf("got outside business hours %s", got)
+		}
+		counts[got.Day()-10]++
+	}
+	// 1 degree of freedom; 10.8 is the 99.9th percentile.
+	mon, tue := draws*60.0/121, draws*61.0/121
+	chi := (float64(counts[0])-mon)*(float64(counts[0])-mon)/mon + (float64(counts[1])-tue)*(float64(counts[1])-tue)/tue
+	if chi > 10.8 {
+		t.Fatalf("day split chi-square too large: %.2f (%v)", chi, counts)
+	}
+
+	// Whole days skip the weekend.
+	s, err = rng.NewDateTimeSampler(rng.DateTimeSpec{
+		Start:       time.Date(2024, 6, 7, 0, 0, 0, 0, loc),
+		End:         time.Date(2024, 6, 10, 0, 0, 0, 0, loc),
+		Granularity: "day",
+		Location:    loc,
+		Weekdays:    true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		got, err := s.Sample(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got.Day() != 7 && got.Day() != 10 {
+			t.Fatalf("got %s want Friday or Monday", got)
+		}
+	}
+
+	// Filtered ranges are enumerated, so their span is bounded.
+	if _, err := rng.NewDateTimeSampler(rng.DateTimeSpec{
+		Start:       time.Date(1000, 1, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC),
+		Granularity: "second",
+		Weekdays:    true,
+	}); err == nil {
+		t.Fatalf("expected error for a filtered range spanning two millennia")
+	}
+}
+
+func TestRandomDuration(t *testing.T) {
+	r := &xorshift32{x: 0xD0}
+	for i := 0; i < 1000; i++ {
+		d, err := rng.RandomDuration(r, nil, 1500*time.Millisecond, 3*time.Second, time.Second)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if d != 2*time.Second && d != 3*time.Second {
+			t.Fatalf("got %s want 2s or 3s", d)
+		}
+	}
+
+	if _, err := rng.RandomDuration(r, nil, 1100*time.Millisecond, 1900*time.Millisecond, time.Second); err == nil {
+		t.Fatalf("expected error for a range with no whole second")
+	}
+}
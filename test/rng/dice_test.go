@@ -0,0 +1,133 @@
+package rng_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestParseDice_Errors(t *testing.T) {
+	bad := []string{
+		"",
+		"   ",
+		"abc",
+		"4d",
+		"0d6",
+		"101d6",
+		"d0",
+		"4d6+",
+		"4d6/2",
+		"2d6kh3",  // keep more than rolled
+		"2d6k1k1", // two keep modifiers
+		"1d1!",    // explodes forever
+		"2d6r<6",  // reroll matches every face
+		"1d6!!",
+		"4d6 x",
+	}
+	for _, in := range bad {
+		if _, err := rng.ParseDice(in); err == nil {
+			t.Fatalf("input=%q expected error", in)
+		}
+	}
+}
+
+func TestDice_RollsStayInRange(t *testing.T) {
+	r := &xorshift32{x: 0xABCDEF01}
+	tests := []struct {
+		expr     string
+		min, max int
+	}{
+		{"d20", 1, 20},
+		{"3d6+2", 5, 20},
+		{"2d8-1d4", -2, 15},
+		{"d%", 1, 100},
+		{"4dF", -4, 4},
+		{"4d6kh3", 3, 18},
+		{"4d6dl1", 3, 18},
+		{"2d20kl1", 1, 20},
+		{"2d6*3", 6, 36},
+		{"2 d 6 r1", 4, 12},
+	}
+
+	for _, tc := range tests {
+		expr, err := rng.ParseDice(tc.expr)
+		if err != nil {
+			t.Fatalf("expr=%q unexpected parse error: %v", tc.expr, err)
+		}
+		for i := 0; i < 2000; i++ {
+			res, err := expr.Roll(r, nil)
+			if err != nil {
+				t.Fatalf("expr=%q unexpected roll error: %v", tc.expr, err)
+			}
+			if res.Total < tc.min || res.Total > tc.max {
+				t.Fatalf("expr=%q total %d outside [%d, %d]", tc.expr, res.Total, tc.min, tc.max)
+			}
+		}
+	}
+}
+
+func TestDice_KeepHighestMarksDice(t *testing.T) {
+	// Counter stream: UniformInt32(1, 6) yields 1, 2, 3, 4 for raw values 0..3.
+	r := &uint32CounterReader{next: 0}
+	expr, err := rng.ParseDice("4d6kh3+2")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	res, err := expr.Roll(r, nil)
+	if err != nil {
+		t.Fatalf("unexpected roll error: %v", err)
+	}
+	dice := res.Terms[0].Dice
+	if len(dice) != 4 || dice[0].Value != 1 || dice[0].Kept {
+		t.Fatalf("expected the 1 to be dropped: %+v", dice)
+	}
+	if res.Terms[0].Value != 9 || res.Total != 11 {
+		t.Fatalf("got term=%d total=%d want 9 and 11", res.Terms[0].Value, res.Total)
+	}
+}
+
+func TestDice_ExplodingAndRerolls(t *testing.T) {
+	// Raw values 5, 5, 0 map to 6, 6, 1 on a d6.
+	r := &scriptedReader{chunks: [][]byte{
+		{0, 0, 0, 5}, {0, 0, 0, 5}, {0, 0, 0, 0},
+	}}
+	expr, _ := rng.ParseDice("1d6!")
+	res, err := expr.Roll(r, nil)
+	if err != nil {
+		t.Fatalf("unexpected roll error: %v", err)
+	}
+	if len(res.Terms[0].Dice) != 3 || res.Total != 13 || !res.Terms[0].Dice[2].Exploded {
+		t.Fatalf("unexpected explosion result: %+v", res)
+	}
+
+	// Raw values 0, 0, 3 map to 1, 1, 4: "ro1" rerolls the first 1 only once.
+	r = &scriptedReader{chunks: [][]byte{{0, 0, 0, 0}, {0, 0, 0, 0}, {0, 0, 0, 3}}}
+	expr, _ = rng.ParseDice("2d6ro1")
+	res, err = expr.Roll(r, nil)
+	if err != nil {
+		t.Fatalf("unexpected roll error: %v", err)
+	}
+	dice := res.Terms[0].Dice
+	if dice[0].Value != 1 || len(dice[0].Rerolls) != 1 || dice[1].Value != 4 {
+		t.Fatalf("unexpected reroll result: %+v", dice)
+	}
+}
+
+func TestDice_TotalOverflowIsOutOfRange(t *testing.T) {
+	r := &xorshift32{x: 1}
+	for _, in := range []string{
+		"1000000*1000000*1000000*9+1000000*1000000*1000000*9",
+		"0-1000000*1000000*1000000*9-1000000*1000000*1000000*9",
+		"1000000*1000000*1000000*1000000",
+	} {
+		expr, err := rng.ParseDice(in)
+		if err != nil {
+			t.Fatalf("%s: unexpected parse error: %v", in, err)
+		}
+		if _, err := expr.Roll(r, nil); !errors.Is(err, rng.ErrDiceOutOfRange) {
+			t.Fatalf("%s: expected ErrDiceOutOfRange, got %v", in, err)
+		}
+	}
+}
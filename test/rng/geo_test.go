@@ -0,0 +1,262 @@
+package rng_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+// binUniform buckets values in [0, 1) into n equal bins.
+func binUniform(values []float64, n int) []int {
+	counts := make([]int, n)
+	for _, v := range values {
+		i := int(v * float64(n))
+		if i == n {
+			i--
+		}
+		counts[i]++
+	}
+	return counts
+}
+
+func TestRandomOnSphere_AreaUniform(t *testing.T) {
+	// On a uniform sphere, sin(lat) and lon are both uniform.
+	r := &xorshift32{x: 0x5FE4E}
+	const draws = 100000
+
+	z := make([]float64, draws)
+	lon := make([]float64, draws)
+	for i := 0; i < draws; i++ {
+		p, err := rng.RandomOnSphere(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		z[i] = (math.Sin(p.Lat*math.Pi/180) + 1) / 2
+		lon[i] = (p.Lon + 180) / 360
+	}
+
+	// 19 degrees of freedom; 50 is far beyond the 99.9th percentile.
+	if chi := chiSquare(binUniform(z, 20), draws/20.0); chi > 50 {
+		t.Fatalf("sin(lat) chi-square too large: %.2f", chi)
+	}
+	if chi := chiSquare(binUniform(lon, 20), draws/20.0); chi > 50 {
+		t.Fatalf("lon chi-square too large: %.2f", chi)
+	}
+}
+
+func TestRandomInBBox_CrossesAntimeridian(t *testing.T) {
+	r := &xorshift32{x: 0xB0B}
+	for i := 0; i < 5000; i++ {
+		p, err := rng.RandomInBBox(r, nil, -10, 170, 10, -170)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Lat < -10 || p.Lat > 10 || (p.Lon > -170 && p.Lon < 170) {
+			t.Fatalf("point outside box: %+v", p)
+		}
+	}
+}
+
+func TestRandomInRadius_AreaUniformWithinCap(t *testing.T) {
+	// The cap area within angle θ is proportional to 1 - cos θ, so that
+	// ratio (normalized by the full cap) must be uniform.
+	r := &xorshift32{x: 0xCA9}
+	center := rng.LatLon{Lat: 60, Lon: 25}
+	const radius = 500_000.0
+	const draws = 50000
+	delta := radius / rng.EarthRadiusMeters
+
+	ratios := make([]float64, draws)
+	for i := 0; i < draws; i++ {
+		p, err := rng.RandomInRadius(r, nil, center, radius)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		theta := haversine(center, p)
+		if theta > delta*(1+1e-9) {
+			t.Fatalf("point %+v is %.0fm away", p, theta*rng.EarthRadiusMeters)
+		}
+		ratios[i] = math.Min((1-math.Cos(theta))/(1-math.Cos(delta)), 1-1e-12)
+	}
+
+	if chi := chiSquare(binUniform(ratios, 10), draws/10.0); chi > 35 {
+		t.Fatalf("cap-area chi-square too large: %.2f", chi)
+	}
+}
+
+func haversine(a, b rng.LatLon) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat, dLon := lat2-lat1, (b.Lon-a.Lon)*math.Pi/180
+	s := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * math.Asin(math.Sqrt(s))
+}
+
+func TestRandomInPolygon_RespectsHoles(t *testing.T) {
+	poly, err := rng.ParseGeoJSONPolygon([]byte(`{
+		"type": "Feature",
+		"geometry": {"type": "Polygon", "coordinates": [
+			[[0,0],[10,0],[10,10],[0,10],[0,0]],
+			[[4,4],[6,4],[6,6],[4,6],[4,4]]
+		]}
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+
+	r := &xorshift32{x: 0x9019}
+	for i := 0; i < 5000; i++ {
+		p, err := rng.RandomInPolygon(r, nil, poly)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Lat < 0 || p.Lat > 10 || p.Lon < 0 || p.Lon > 10 {
+			t.Fatalf("point outside polygon: %+v", p)
+		}
+		if p.Lat > 4 && p.Lat < 6 && p.Lon > 4 && p.Lon < 6 {
+			t.Fatalf("point inside hole: %+v", p)
+		}
+	}
+
+	for _, bad := range []string{`{}`, `{"type":"Point","coordinates":[0,0]}`, `not json`} {
+		if _, err := rng.ParseGeoJSONPolygon([]byte(bad)); err == nil {
+			t.Fatalf("input=%s expected error", bad)
+		}
+	}
+}
+
+func TestRandomPoints_BallAndSimplexUniform(t *testing.T) {
+	r := &xorshift32{x: 0xBA11}
+	const draws = 50000
+
+	// Uniform in a 3-ball of radius 2: (|x|/2)^3 is uniform.
+	ball := make([]float64, draws)
+	for i := 0; i < draws; i++ {
+		v, err := rng.RandomInBall(r, nil, 3, 2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		norm := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+		if norm > 2 {
+			t.Fatalf("point outside ball: %v", v)
+		}
+		ball[i] = math.Pow(norm/2, 3)
+	}
+	if chi := chiSquare(binUniform(ball, 10), draws/10.0); chi > 35 {
+		t.Fatalf("ball chi-square too large: %.2f", chi)
+	}
+
+	// Uniform in the standard 4-simplex: sum(x)^4 is uniform.
+	simplex := make([]float64, draws)
+	for i := 0; i < draws; i++ {
+		v, err := rng.RandomInSimplex(r, nil, 4)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sum := 0.0
+		for _, x := range v {
+			if x < 0 {
+				t.Fatalf("negative coordinate: %v", v)
+			}
+			sum += x
+		}
+		if sum > 1+1e-12 {
+			t.Fatalf("point outside simplex: %v", v)
+		}
+		simplex[i] = math.Min(math.Pow(sum, 4), 1-1e-12)
+	}
+	if chi := chiSquare(binUniform(simplex, 10), draws/10.0); chi > 35 {
+		t.Fatalf("simplex chi-square too large: %.2f", chi)
+	}
+
+	// Unit vectors have unit length.
+	v, err := rng.RandomUnitVector(r, nil, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	norm := 0.0
+	for _, x := range v {
+		norm += x * x
+	}
+	if math.Abs(norm-1) > 1e-12 {
+		t.Fatalf("unit vector has squared norm %v", norm)
+	}
+}
+
+func TestRandomInPolygon_ThinDiagonalNeverGivesUp(t *testing.T) {
+	// A sliver along the diagonal covers a tiny share of its bounding box.
+	poly := rng.Polygon{{{0, 0}, {50, 50}, {50, 50.001}, {0, 0.001}}}
+	s, err := rng.NewPolygonSampler(poly)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := &xorshift32{x: 0x5117}
+	for i := 0; i < 5000; i++ {
+		p, err := s.Sample(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Lon < 0 || p.Lon > 50 || p.Lat < p.Lon-1e-6 || p.Lat > p.Lon+0.001+1e-6 {
+			t.Fatalf("point outside sliver: %+v", p)
+		}
+	}
+
+	for _, bad := range []rng.Polygon{
+		nil,
+		{{{0, 0}, {1, 1}, {2, 2}}},         // no area
+		{{{0, 0}, {1, 1}, {1, 0}, {0, 1}}}, // bow tie
+	} {
+		if _, err := rng.NewPolygonSampler(bad); err == nil {
+			t.Fatalf("polygon=%v expected error", bad)
+		}
+	}
+}
+
+func TestRandomInPolygon_AreaUniform(t *testing.T) {
+	r := &xorshift32{x: 0xD1A}
+	const draws = 40000
+
+	// Near the equator a diamond's four quadrants have equal area.
+	diamond := rng.Polygon{{{0, 0}, {1, 1}, {2, 0}, {1, -1}}}
+	counts := make([]int, 4)
+	for i := 0; i < draws; i++ {
+		p, err := rng.RandomInPolygon(r, nil, diamond)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		q := 0
+		if p.Lon > 1 {
+			q++
+		}
+		if p.Lat > 0 {
+			q += 2
+		}
+		counts[q]++
+	}
+	// 3 degrees of freedom; 16.3 is the 99.9th percentile.
+	if chi := chiSquare(counts, draws/4.0); chi > 16.3 {
+		t.Fatalf("quadrant chi-square too large: %.2f (%v)", chi, counts)
+	}
+
+	// A tall rectangle splits its spherical area in half at asin(sin 80° / 2),
+	// not at 40°.
+	rect := rng.Polygon{{{0, 0}, {1, 0}, {1, 80}, {0, 80}}}
+	split := math.Asin(math.Sin(80*math.Pi/180)/2) * 180 / math.Pi
+	halves := make([]int, 2)
+	for i := 0; i < draws; i++ {
+		p, err := rng.RandomInPolygon(r, nil, rect)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if p.Lat > split {
+			halves[1]++
+		} else {
+			halves[0]++
+		}
+	}
+	// 1 degree of freedom; 10.8 is the 99.9th percentile.
+	if chi := chiSquare(halves, draws/2.0); chi > 10.8 {
+		t.Fatalf("latitude split chi-square too large: %.2f (%v)", chi, halves)
+	}
+}
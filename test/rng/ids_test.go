@@ -0,0 +1,112 @@
+package rng_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestNewUUIDv7_LayoutAndTimestamp(t *testing.T) {
+	now := time.UnixMilli(0x0189_5F3C_1A2B)
+	id, err := rng.NewUUIDv7(&xorshift32{x: 7}, nil, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	re := regexp.MustCompile(`^01895f3c-1a2b-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !re.MatchString(id) {
+		t.Fatalf("bad uuid v7 %q", id)
+	}
+}
+
+func TestULID_KnownTimestampAndZeroEntropy(t *testing.T) {
+	var g rng.ULIDGenerator
+	r := bytes.NewReader(make([]byte, 10))
+	id, err := g.Next(r, nil, time.UnixMilli(1469918176385), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Timestamp prefix from the ULID spec example.
+	if id != "01ARYZ6S41"+"0000000000000000" {
+		t.Fatalf("got %q", id)
+	}
+}
+
+func TestULID_MonotonicWithinMillisecond(t *testing.T) {
+	var g rng.ULIDGenerator
+	now := time.UnixMilli(1_700_000_000_000)
+	r := &xorshift32{x: 0x0111D}
+
+	prev := ""
+	for i := 0; i < 100; i++ {
+		id, err := g.Next(r, nil, now, true)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(id) != 26 || id <= prev {
+			t.Fatalf("id %q does not sort after %q", id, prev)
+		}
+		prev = id
+	}
+
+	// A clock step backwards keeps the order.
+	id, err := g.Next(r, nil, now.Add(-time.Second), true)
+	if err != nil || id <= prev {
+		t.Fatalf("id %q does not sort after %q (err %v)", id, prev, err)
+	}
+}
+
+func TestULID_MonotonicOverflow(t *testing.T) {
+	var g rng.ULIDGenerator
+	now := time.UnixMilli(1_700_000_000_000)
+	r := &scriptedReader{chunks: [][]byte{bytes.Repeat([]byte{0xFF}, 10)}}
+
+	if _, err := g.Next(r, nil, now, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := g.Next(r, nil, now, true); !errors.Is(err, rng.ErrULIDOverflow) {
+		t.Fatalf("expected overflow, got %v", err)
+	}
+}
+
+func TestRandomToken_Encodings(t *testing.T) {
+	r := &xorshift32{x: 0x70C}
+	for _, n := range []int{1, 5, 16, 33} {
+		s, err := rng.RandomToken(r, nil, n, "base32")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want := (8*n + 4) / 5; len(s) != want {
+			t.Fatalf("base32 of %d bytes has length %d want %d", n, len(s), want)
+		}
+		if !regexp.MustCompile(`^[` + rng.CrockfordAlphabet + `]+$`).MatchString(s) {
+			t.Fatalf("base32 token %q has characters outside the Crockford alphabet", s)
+		}
+
+		s, err = rng.RandomToken(r, nil, n, "base64url")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil || len(raw) != n {
+			t.Fatalf("base64url token %q does not decode to %d bytes", s, n)
+		}
+	}
+
+	if _, err := rng.RandomToken(r, nil, 16, "hex"); err == nil {
+		t.Fatalf("expected error for unknown encoding")
+	}
+	if _, err := rng.RandomToken(r, nil, 0, "base32"); err == nil {
+		t.Fatalf("expected error for empty token")
+	}
+}
+
+func TestNanoIDAlphabet_Distinct(t *testing.T) {
+	if got := len(rng.FilterCharset([]rune(rng.NanoIDAlphabet), "")); got != 64 {
+		t.Fatalf("alphabet has %d distinct symbols want 64", got)
+	}
+}
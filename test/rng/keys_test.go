@@ -0,0 +1,161 @@
+package rng_test
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+// RFC 8032 section 7.1, test 1.
+func TestGenerateKeyPair_Ed25519FromSeed(t *testing.T) {
+	seed, _ := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	key, err := rng.GenerateKeyPair(bytes.NewReader(seed), nil, "ed25519", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pub := hex.EncodeToString(key.Public.(ed25519.PublicKey))
+	if pub != "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a" {
+		t.Fatalf("unexpected public key %s", pub)
+	}
+}
+
+func TestGenerateKeyPair_WireGuardClamped(t *testing.T) {
+	key, err := rng.GenerateKeyPair(bytes.NewReader(bytes.Repeat([]byte{0xFF}, 32)), nil, "wireguard", 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	priv, pub, err := key.Encode("base64")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	raw, _ := base64.StdEncoding.DecodeString(priv)
+	if len(raw) != 32 || raw[0] != 0xF8 || raw[31] != 0x7F {
+		t.Fatalf("key is not clamped: %x", raw)
+	}
+	if rawPub, _ := base64.StdEncoding.DecodeString(pub); len(rawPub) != 32 {
+		t.Fatalf("unexpected public key length %d", len(rawPub))
+	}
+}
+
+func TestGenerateKeyPair_PEMRoundTripAndSign(t *testing.T) {
+	r := &xorshift32{x: 0x6E4}
+	digest := sha256.Sum256([]byte("random"))
+
+	for _, kind := range []string{"ed25519", "x25519", "p256", "p384", "rsa"} {
+		key, err := rng.GenerateKeyPair(r, nil, kind, 2048)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kind, err)
+		}
+		privPEM, pubPEM, err := key.Encode("pem")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kind, err)
+		}
+
+		block, _ := pem.Decode([]byte(privPEM))
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			t.Fatalf("%s: private key does not parse: %v", kind, err)
+		}
+		block, _ = pem.Decode([]byte(pubPEM))
+		parsedPub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			t.Fatalf("%s: public key does not parse: %v", kind, err)
+		}
+
+		switch priv := parsed.(type) {
+		case ed25519.PrivateKey:
+			sig := ed25519.Sign(priv, digest[:])
+			if !ed25519.Verify(parsedPub.(ed25519.PublicKey), digest[:], sig) {
+				t.Fatalf("%s: signature does not verify", kind)
+			}
+		case *ecdh.PrivateKey:
+			if !priv.PublicKey().Equal(parsedPub) {
+				t.Fatalf("%s: public key mismatch", kind)
+			}
+		case *ecdsa.PrivateKey:
+			sig, err := ecdsa.SignASN1(r, priv, digest[:])
+			if err != nil || !ecdsa.VerifyASN1(parsedPub.(*ecdsa.PublicKey), digest[:], sig) {
+				t.Fatalf("%s: signature does not verify (%v)", kind, err)
+			}
+		case *rsa.PrivateKey:
+			if priv.N.BitLen() != 2048 || priv.Validate() != nil {
+				t.Fatalf("%s: invalid key", kind)
+			}
+		}
+	}
+}
+
+func TestKeyPair_OpenSSH(t *testing.T) {
+	r := &xorshift32{x: 0x55A}
+	for _, kind := range []string{"ed25519", "p256", "p384"} {
+		key, err := rng.GenerateKeyPair(r, nil, kind, 0)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kind, err)
+		}
+		priv, pub, err := key.Encode("openssh")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kind, err)
+		}
+		signer, err := ssh.ParsePrivateKey([]byte(priv))
+		if err != nil {
+			t.Fatalf("%s: private key does not parse: %v", kind, err)
+		}
+		got := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+		if got != pub {
+			t.Fatalf("%s: public key mismatch:\n%s\n%s", kind, got, pub)
+		}
+	}
+
+	key, _ := rng.GenerateKeyPair(r, nil, "x25519", 0)
+	if _, _, err := key.Encode("openssh"); err == nil {
+		t.Fatalf("expected error for x25519 openssh")
+	}
+}
+
+func TestGenerateKeyPair_Errors(t *testing.T) {
+	r := &xorshift32{x: 1}
+	if _, err := rng.GenerateKeyPair(r, nil, "dsa", 0); err == nil {
+		t.Fatalf("expected error for unknown type")
+	}
+	if _, err := rng.GenerateKeyPair(r, nil, "rsa", 1024); err == nil {
+		t.Fatalf("expected error for small rsa key")
+	}
+	for _, kind := range []string{"p256", "rsa"} {
+		h := rng.NewHealth()
+		h.Set(true, "")
+		if _, err := rng.GenerateKeyPair(bytes.NewReader(nil), h, kind, 2048); err == nil {
+			t.Fatalf("%s: expected error for failing reader", kind)
+		}
+		if ok, _, _ := h.Snapshot(); ok {
+			t.Fatalf("%s: failing reader should mark the RNG unhealthy", kind)
+		}
+	}
+}
+
+func TestGenerateKeyPair_RSAUsesReader(t *testing.T) {
+	// The same entropy must give the same key; crypto/rand would not.
+	var moduli []string
+	for i := 0; i < 2; i++ {
+		key, err := rng.GenerateKeyPair(&xorshift32{x: 0x45A}, nil, "rsa", 2048)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		moduli = append(moduli, key.Public.(*rsa.PublicKey).N.String())
+	}
+	if moduli[0] != moduli[1] {
+		t.Fatalf("rsa key does not come from the reader")
+	}
+}
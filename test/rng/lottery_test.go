@@ -0,0 +1,90 @@
+package rng_test
+
+import (
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestSampleDistinct_DistinctAndInRange(t *testing.T) {
+	r := &xorshift32{x: 0xDEADBEEF}
+
+	for i := 0; i < 2000; i++ {
+		picked, err := rng.SampleDistinct(r, nil, 1, 49, 6)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(picked) != 6 {
+			t.Fatalf("got %d numbers want 6", len(picked))
+		}
+		seen := map[int]bool{}
+		for _, v := range picked {
+			if v < 1 || v > 49 {
+				t.Fatalf("out of range: %d", v)
+			}
+			if seen[v] {
+				t.Fatalf("duplicate %d in %v", v, picked)
+			}
+			seen[v] = true
+		}
+	}
+
+	// Whole range: must be a permutation.
+	all, err := rng.SampleDistinct(r, nil, -2, 2, 5)
+	if err != nil || len(all) != 5 {
+		t.Fatalf("full-range sample failed: %v %v", all, err)
+	}
+
+	if _, err := rng.SampleDistinct(r, nil, 1, 5, 6); err == nil {
+		t.Fatalf("expected error when count exceeds range")
+	}
+}
+
+func TestSampleDistinct_UniformInclusionAndOrder(t *testing.T) {
+	// Every value should be picked equally often, and lead the draw equally often.
+	r := &xorshift32{x: 0x12345678}
+	const n, k, draws = 10, 3, 200000
+
+	included := make([]int, n)
+	first := make([]int, n)
+	for i := 0; i < draws; i++ {
+		picked, err := rng.SampleDistinct(r, nil, 0, n-1, k)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, v := range picked {
+			included[v]++
+		}
+		first[picked[0]]++
+	}
+
+	if chi := chiSquare(included, float64(draws*k)/n); chi > 40 {
+		t.Fatalf("inclusion chi-square too large: %.2f", chi)
+	}
+	if chi := chiSquare(first, float64(draws)/n); chi > 40 {
+		t.Fatalf("first-ball chi-square too large: %.2f", chi)
+	}
+}
+
+func TestDrawLottery_SharedBonusNeverRepeatsMain(t *testing.T) {
+	pools, ok := rng.LotteryPreset("6/49")
+	if !ok {
+		t.Fatalf("missing 6/49 preset")
+	}
+
+	r := &xorshift32{x: 42}
+	for i := 0; i < 1000; i++ {
+		draws, err := rng.DrawLottery(r, nil, pools)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(draws[0]) != 6 || len(draws[1]) != 1 {
+			t.Fatalf("unexpected draw sizes: %v", draws)
+		}
+		for _, v := range draws[0] {
+			if v == draws[1][0] {
+				t.Fatalf("bonus %d repeats a main ball %v", v, draws[0])
+			}
+		}
+	}
+}
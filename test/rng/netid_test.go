@@ -0,0 +1,115 @@
+package rng_test
+
+import (
+	"bytes"
+	"net/netip"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestRandomMAC_LocallyAdministeredUnicast(t *testing.T) {
+	r := &xorshift32{x: 0x3AC}
+	for i := 0; i < 500; i++ {
+		mac, err := rng.RandomMAC(r, nil, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if mac[0]&0x01 != 0 || mac[0]&0x02 == 0 {
+			t.Fatalf("mac %s is not locally administered unicast", mac)
+		}
+	}
+
+	mac, err := rng.RandomMAC(bytes.NewReader([]byte{0xFF, 0xFF, 0xFF}), nil, []byte{0x00, 0x1A, 0x2B})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mac.String() != "00:1a:2b:ff:ff:ff" {
+		t.Fatalf("got %s", mac)
+	}
+
+	if _, err := rng.RandomMAC(r, nil, []byte{0x01}); err == nil {
+		t.Fatalf("expected error for multicast prefix")
+	}
+	if _, err := rng.RandomMAC(r, nil, make([]byte, 6)); err == nil {
+		t.Fatalf("expected error for long prefix")
+	}
+}
+
+func TestRandomIP_ExcludesNetworkAndBroadcast(t *testing.T) {
+	r := &xorshift32{x: 0x1B}
+
+	// /30 has exactly two usable hosts.
+	counts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		addr, err := rng.RandomIP(r, nil, netip.MustParsePrefix("192.0.2.4/30"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts[addr.String()]++
+	}
+	if len(counts) != 2 || counts["192.0.2.5"] == 0 || counts["192.0.2.6"] == 0 {
+		t.Fatalf("unexpected /30 hosts %v", counts)
+	}
+
+	tests := []struct {
+		cidr string
+		want map[string]bool
+	}{
+		{"192.0.2.8/31", map[string]bool{"192.0.2.8": true, "192.0.2.9": true}},
+		{"192.0.2.77/32", map[string]bool{"192.0.2.77": true}},
+		{"2001:db8::/127", map[string]bool{"2001:db8::1": true}},
+		{"2001:db8::5/128", map[string]bool{"2001:db8::5": true}},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			addr, err := rng.RandomIP(r, nil, netip.MustParsePrefix(tt.cidr))
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.cidr, err)
+			}
+			if !tt.want[addr.String()] {
+				t.Fatalf("%s: unexpected address %s", tt.cidr, addr)
+			}
+		}
+	}
+}
+
+func TestRandomIP_StaysInsideLargePrefixes(t *testing.T) {
+	r := &xorshift32{x: 0x6}
+	for _, cidr := range []string{"10.0.0.0/8", "0.0.0.0/0", "2001:db8::/32", "::/0", "fd00:1:2:3::/64"} {
+		prefix := netip.MustParsePrefix(cidr)
+		for i := 0; i < 200; i++ {
+			addr, err := rng.RandomIP(r, nil, prefix)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", cidr, err)
+			}
+			if !prefix.Contains(addr) || addr == prefix.Addr() {
+				t.Fatalf("%s: bad address %s", cidr, addr)
+			}
+		}
+	}
+}
+
+func TestRandomPorts_DistinctAndExcluded(t *testing.T) {
+	r := &xorshift32{x: 0x9027}
+	exclude := map[int]bool{8080: true, 8081: true, 8082: true}
+
+	ports, err := rng.RandomPorts(r, nil, 8080, 8089, exclude, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	seen := map[int]bool{}
+	for _, p := range ports {
+		if p < 8083 || p > 8089 || seen[p] {
+			t.Fatalf("unexpected ports %v", ports)
+		}
+		seen[p] = true
+	}
+
+	if _, err := rng.RandomPorts(r, nil, 8080, 8089, exclude, 8); err == nil {
+		t.Fatalf("expected error when asking for more ports than available")
+	}
+	if _, err := rng.RandomPorts(r, nil, 0, 10, nil, 1); err == nil {
+		t.Fatalf("expected error for port 0")
+	}
+}
@@ -0,0 +1,130 @@
+package rng_test
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+// RFC 4226 appendix D.
+func TestOTPCode_HOTPVectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	want := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range want {
+		got, err := rng.OTPCode(key, "SHA1", 6, uint64(counter))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != code {
+			t.Fatalf("counter %d: got %s want %s", counter, got, code)
+		}
+	}
+}
+
+// RFC 6238 appendix B.
+func TestVerifyOTP_TOTPVectors(t *testing.T) {
+	keys := map[string][]byte{
+		"SHA1":   []byte("12345678901234567890"),
+		"SHA256": []byte("12345678901234567890123456789012"),
+		"SHA512": []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	tests := []struct {
+		unix      int64
+		algorithm string
+		code      string
+	}{
+		{59, "SHA1", "94287082"},
+		{59, "SHA256", "46119246"},
+		{59, "SHA512", "90693936"},
+		{1111111109, "SHA1", "07081804"},
+		{1234567890, "SHA256", "91819424"},
+		{2000000000, "SHA512", "38618901"},
+		{20000000000, "SHA1", "65353130"},
+	}
+	for _, tt := range tests {
+		spec := rng.OTPSpec{Type: "totp", Algorithm: tt.algorithm, Digits: 8, Period: 30}
+		ok, offset, err := rng.VerifyOTP(keys[tt.algorithm], tt.code, spec, time.Unix(tt.unix, 0), 0)
+		if err != nil || !ok || offset != 0 {
+			t.Fatalf("%d %s: ok=%v offset=%d err=%v", tt.unix, tt.algorithm, ok, offset, err)
+		}
+	}
+}
+
+func TestVerifyOTP_Window(t *testing.T) {
+	key := []byte("12345678901234567890")
+	spec := rng.OTPSpec{Type: "totp", Algorithm: "SHA1", Digits: 8, Period: 30}
+
+	// "94287082" is the code for step 1; at step 2 it is one step behind.
+	ok, offset, _ := rng.VerifyOTP(key, "94287082", spec, time.Unix(60, 0), 1)
+	if !ok || offset != -1 {
+		t.Fatalf("expected match at offset -1, got ok=%v offset=%d", ok, offset)
+	}
+	if ok, _, _ := rng.VerifyOTP(key, "94287082", spec, time.Unix(60, 0), 0); ok {
+		t.Fatalf("expected no match with window 0")
+	}
+
+	hotp := rng.OTPSpec{Type: "hotp", Algorithm: "SHA1", Digits: 6, Counter: 2}
+	if ok, offset, _ := rng.VerifyOTP(key, "969429", hotp, time.Time{}, 1); !ok || offset != 1 {
+		t.Fatalf("expected hotp match at offset 1, got ok=%v offset=%d", ok, offset)
+	}
+	if ok, _, _ := rng.VerifyOTP(key, "287082", hotp, time.Time{}, 1); ok {
+		t.Fatalf("hotp must not accept earlier counters")
+	}
+}
+
+func TestNewOTPSecret_RoundTripAndURI(t *testing.T) {
+	raw := bytes.Repeat([]byte{0xA5}, 20)
+	secret, err := rng.NewOTPSecret(bytes.NewReader(raw), nil, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(secret) != 32 || strings.Contains(secret, "=") {
+		t.Fatalf("unexpected secret %q", secret)
+	}
+	key, err := rng.DecodeOTPSecret(strings.ToLower(secret[:16]) + " " + secret[16:])
+	if err != nil || !bytes.Equal(key, raw) {
+		t.Fatalf("secret does not round-trip: %x (%v)", key, err)
+	}
+
+	uri, err := rng.OTPURI(secret, rng.OTPSpec{
+		Type: "totp", Issuer: "Lost Woods", Account: "alice@example.com",
+		Algorithm: "SHA256", Digits: 8, Period: 60,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Lost Woods:alice@example.com" {
+		t.Fatalf("unexpected uri %q", uri)
+	}
+	q := u.Query()
+	if q.Get("secret") != secret || q.Get("issuer") != "Lost Woods" || q.Get("algorithm") != "SHA256" ||
+		q.Get("digits") != "8" || q.Get("period") != "60" {
+		t.Fatalf("unexpected query %v", q)
+	}
+}
+
+func TestOTP_Errors(t *testing.T) {
+	for _, n := range []int{8, 15, 65} {
+		if _, err := rng.NewOTPSecret(&xorshift32{x: 1}, nil, n); err == nil {
+			t.Fatalf("expected error for %d-byte secret", n)
+		}
+	}
+	if _, err := rng.DecodeOTPSecret("not base32!"); err == nil {
+		t.Fatalf("expected error for invalid secret")
+	}
+	for _, spec := range []rng.OTPSpec{
+		{Type: "motp", Algorithm: "SHA1", Digits: 6, Period: 30},
+		{Type: "totp", Algorithm: "MD5", Digits: 6, Period: 30},
+		{Type: "totp", Algorithm: "SHA1", Digits: 9, Period: 30},
+		{Type: "totp", Algorithm: "SHA1", Digits: 6, Period: 0},
+	} {
+		if err := spec.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", spec)
+		}
+	}
+}
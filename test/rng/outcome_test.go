@@ -0,0 +1,83 @@
+package rng_test
+
+import (
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func percentProbs(t *testing.T, in ...string) []rng.Probability {
+	t.Helper()
+	out := make([]rng.Probability, len(in))
+	for i, s := range in {
+		p, err := rng.ParsePercentProbability(s)
+		if err != nil {
+			t.Fatalf("input=%q unexpected error: %v", s, err)
+		}
+		out[i] = p
+	}
+	return out
+}
+
+func TestNewOutcomeTable_CommonDenominator(t *testing.T) {
+	table, err := rng.NewOutcomeTable(percentProbs(t, "70", "25", "4.9", "0.1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table.Den.Int64() != 1000 {
+		t.Fatalf("got denominator %s want 1000", table.Den)
+	}
+	want := []int64{700, 250, 49, 1}
+	for i, w := range table.Weights {
+		if w.Int64() != want[i] {
+			t.Fatalf("weight %d got %s want %d", i, w, want[i])
+		}
+	}
+
+	// 0% and 100% reduce to 0/1 and 1/1 but still combine.
+	if _, err := rng.NewOutcomeTable(percentProbs(t, "0", "100")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewOutcomeTable_MustSumToExactly100(t *testing.T) {
+	bad := [][]string{
+		{"70", "25", "4.9"},
+		{"50", "50.0000000000001"},
+		{"33.333", "33.333", "33.333"},
+	}
+	for _, in := range bad {
+		if _, err := rng.NewOutcomeTable(percentProbs(t, in...)); err == nil {
+			t.Fatalf("input=%q expected error", in)
+		}
+	}
+}
+
+func TestOutcomeTable_RollCoversEveryOutcomeExactly(t *testing.T) {
+	// Denominator 1000 over a full counter cycle of 1000 accepted values
+	// hits every roll exactly once.
+	table, err := rng.NewOutcomeTable(percentProbs(t, "70", "25", "4.9", "0.1"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := &uint32CounterReader{next: 0}
+	counts := make([]int, 4)
+	for i := 0; i < 1000; i++ {
+		idx, roll, err := table.Roll(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if roll.Int64() != int64(i+1) {
+			t.Fatalf("roll %d got %s", i, roll)
+		}
+		counts[idx]++
+	}
+
+	want := []int{700, 250, 49, 1}
+	for i := range want {
+		if counts[i] != want[i] {
+			t.Fatalf("outcome %d count=%d want=%d", i, counts[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package rng_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestWordlists_Embedded(t *testing.T) {
+	for name, size := range map[string]int{"large": 7776, "short": 1296} {
+		words, ok := rng.Wordlist(name)
+		if !ok {
+			t.Fatalf("missing wordlist %s", name)
+		}
+		if len(words) != size {
+			t.Fatalf("wordlist %s has %d words want %d", name, len(words), size)
+		}
+		seen := map[string]bool{}
+		for _, w := range words {
+			if seen[w] {
+				t.Fatalf("wordlist %s has duplicate %q", name, w)
+			}
+			seen[w] = true
+		}
+	}
+	if _, ok := rng.Wordlist("original"); ok {
+		t.Fatalf("unexpected wordlist")
+	}
+}
+
+func TestRandomPassphrase_ShapeAndEntropy(t *testing.T) {
+	words, _ := rng.Wordlist("large")
+	r := &xorshift32{x: 0xD1CE}
+
+	phrase, bits, err := rng.RandomPassphrase(r, nil, rng.PassphraseSpec{
+		Words: words, Count: 6, Separator: " ",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len(strings.Split(phrase, " ")); n != 6 {
+		t.Fatalf("got %d words in %q", n, phrase)
+	}
+	if want := 6 * math.Log2(7776); math.Abs(bits-want) > 1e-9 {
+		t.Fatalf("entropy got %v want %v", bits, want)
+	}
+
+	phrase, bits, err = rng.RandomPassphrase(r, nil, rng.PassphraseSpec{
+		Words: words, Count: 4, Separator: " ", Capitalize: "first", Digits: 2, Symbols: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parts := strings.Split(phrase, " ")
+	injected := 0
+	for _, p := range parts {
+		if !unicode.IsUpper(rune(p[0])) {
+			t.Fatalf("word %q not capitalized in %q", p, phrase)
+		}
+		if strings.ContainsAny(p, "0123456789") {
+			injected++
+		}
+	}
+	if injected != 1 {
+		t.Fatalf("expected digits in exactly one word: %q", phrase)
+	}
+	want := 4*math.Log2(7776) + 2 + 2*math.Log2(10) + math.Log2(28)
+	if math.Abs(bits-want) > 1e-9 {
+		t.Fatalf("entropy got %v want %v", bits, want)
+	}
+
+	if _, _, err := rng.RandomPassphrase(r, nil, rng.PassphraseSpec{Words: words, Count: 3, Separator: " ", Capitalize: "title"}); err == nil {
+		t.Fatalf("expected error for unknown capitalize mode")
+	}
+}
+
+func TestRandomPassphrase_HyphenatedWordsLeftOut(t *testing.T) {
+	words := []string{"yo-yo", "cat", "t-shirt", "dog"}
+	r := &xorshift32{x: 0x7E5}
+
+	for _, sep := range []string{"-", "_-_"} {
+		phrase, bits, err := rng.RandomPassphrase(r, nil, rng.PassphraseSpec{Words: words, Count: 50, Separator: sep})
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", sep, err)
+		}
+		if strings.Contains(phrase, "yo") || strings.Contains(phrase, "shirt") {
+			t.Fatalf("%q: hyphenated word in %q", sep, phrase)
+		}
+		if bits != 50 {
+			t.Fatalf("%q: entropy got %v want 50", sep, bits)
+		}
+	}
+
+	// The large list has four hyphenated words; other separators keep them.
+	large, _ := rng.Wordlist("large")
+	if _, bits, _ := rng.RandomPassphrase(r, nil, rng.PassphraseSpec{Words: large, Count: 1, Separator: "-"}); bits != math.Log2(7772) {
+		t.Fatalf("entropy got %v want log2(7772)", bits)
+	}
+	if _, bits, _ := rng.RandomPassphrase(r, nil, rng.PassphraseSpec{Words: large, Count: 1, Separator: "."}); bits != math.Log2(7776) {
+		t.Fatalf("entropy got %v want log2(7776)", bits)
+	}
+
+	// Without a separator the words run together and the entropy would be
+	// overstated, so it is rejected.
+	if _, _, err := rng.RandomPassphrase(r, nil, rng.PassphraseSpec{Words: large, Count: 2}); err == nil {
+		t.Fatalf("expected error for an empty separator")
+	}
+}
@@ -0,0 +1,89 @@
+package rng_test
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestParsePattern_Shapes(t *testing.T) {
+	tests := []struct {
+		pattern string
+		re      string
+		bits    float64
+	}{
+		{"Cvccvc99", `^[BCDFGHJKLMNPQRSTVWXYZ][aeiou][bcdfghjklmnpqrstvwxyz]{2}[aeiou][bcdfghjklmnpqrstvwxyz][0-9]{2}$`,
+			4*math.Log2(21) + 2*math.Log2(5) + 2*math.Log2(10)},
+		{"(XXXX-){3}XXXX", `^[A-Z0-9]{4}-[A-Z0-9]{4}-[A-Z0-9]{4}-[A-Z0-9]{4}$`, 16 * math.Log2(36)},
+		{`'a9'\X#{3}`, `^a9X[0-9]{3}$`, 3 * math.Log2(10)},
+		{"Ss", `^[BDFGHJKLMNPRSTVZ][aeiou][lmnrst]?[bdfghjklmnprstvz][aeiou][lmnrst]?$`, 2 * math.Log2(560)},
+		{"((a){2}-){2}", `^[a-z]{2}-[a-z]{2}-$`, 4 * math.Log2(26)},
+		{"é{0}ü", `^ü$`, 0},
+	}
+
+	r := &xorshift32{x: 0x9A77}
+	for _, tt := range tests {
+		p, err := rng.ParsePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.pattern, err)
+		}
+		if got := p.EntropyBits(); math.Abs(got-tt.bits) > 1e-9 {
+			t.Fatalf("%q: entropy %v want %v", tt.pattern, got, tt.bits)
+		}
+		re := regexp.MustCompile(tt.re)
+		for i := 0; i < 200; i++ {
+			s, err := p.Generate(r, nil)
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %v", tt.pattern, err)
+			}
+			if !re.MatchString(s) {
+				t.Fatalf("%q generated %q", tt.pattern, s)
+			}
+		}
+	}
+}
+
+func TestParsePattern_Errors(t *testing.T) {
+	for _, pattern := range []string{
+		"",
+		"X{0}",
+		"(XX",
+		"XX)",
+		"'abc",
+		`X\`,
+		"{3}",
+		"X{",
+		"X{-1}",
+		"X{1001}",
+		"(X{1000}){2}",
+		strings.Repeat("(", 12) + "X" + strings.Repeat(")", 12),
+	} {
+		if _, err := rng.ParsePattern(pattern); err == nil {
+			t.Fatalf("expected error for %q", pattern)
+		}
+	}
+}
+
+func TestPattern_DigitsUniform(t *testing.T) {
+	p, err := rng.ParsePattern("9")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := &xorshift32{x: 0x99}
+	const draws = 20000
+	counts := make([]int, 10)
+	for i := 0; i < draws; i++ {
+		s, err := p.Generate(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts[s[0]-'0']++
+	}
+	// df = 9, p≈0.001 critical value ≈ 27.9.
+	if chi := chiSquare(counts, draws/10.0); chi > 27.9 {
+		t.Fatalf("chi-square too high: %.2f", chi)
+	}
+}
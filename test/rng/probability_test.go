@@ -0,0 +1,101 @@
+package rng_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestParseProbabilities(t *testing.T) {
+	tests := []struct {
+		parse   func(string) (rng.Probability, error)
+		in      string
+		wantNum string
+		wantDen string
+		wantErr bool
+	}{
+		{rng.ParsePercentProbability, "25", "25", "100", false},
+		{rng.ParsePercentProbability, "0", "0", "1", false},
+		{rng.ParsePercentProbability, "100.000", "1", "1", false},
+		{rng.ParsePercentProbability, "1.23456789", "123456789", "10000000000", false},
+		{rng.ParsePercentProbability, "0.0000000001%", "1", "1000000000000", false},
+		{rng.ParsePercentProbability, "100.0000000000001", "", "", true},
+		{rng.ParsePercentProbability, "-1", "", "", true},
+
+		{rng.ParseFractionProbability, "1/3", "1", "3", false},
+		{rng.ParseFractionProbability, "2/6", "1", "3", false},
+		{rng.ParseFractionProbability, "0.25", "1", "4", false},
+		{rng.ParseFractionProbability, "1/1000000000000", "1", "1000000000000", false},
+		{rng.ParseFractionProbability, "4/3", "", "", true},
+		{rng.ParseFractionProbability, "1/0", "", "", true},
+		{rng.ParseFractionProbability, "-1/3", "", "", true},
+		{rng.ParseFractionProbability, "1e-3", "", "", true},
+
+		{rng.ParseOddsProbability, "1:4", "1", "5", false},
+		{rng.ParseOddsProbability, "3:0", "3", "3", false},
+		{rng.ParseOddsProbability, "0:0", "", "", true},
+		{rng.ParseOddsProbability, "1/4", "", "", true},
+		{rng.ParseOddsProbability, "-1:4", "", "", true},
+	}
+
+	for _, tc := range tests {
+		got, err := tc.parse(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("input=%q expected error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("input=%q unexpected error: %v", tc.in, err)
+		}
+		if got.Num.String() != tc.wantNum || got.Den.String() != tc.wantDen {
+			t.Fatalf("input=%q got %s/%s want %s/%s", tc.in, got.Num, got.Den, tc.wantNum, tc.wantDen)
+		}
+	}
+}
+
+func TestUniformBigInt_MatchesUniformInt32OnSmallRanges(t *testing.T) {
+	a := &xorshift32{x: 0x5EED}
+	b := &xorshift32{x: 0x5EED}
+
+	for _, n := range []int{1, 7, 100, 1000, 1_000_000_000} {
+		for i := 0; i < 1000; i++ {
+			want, err := rng.UniformInt32(a, nil, 0, n-1)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got, err := rng.UniformBigInt(b, nil, big.NewInt(int64(n)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Int64() != int64(want) {
+				t.Fatalf("n=%d got %s want %d", n, got, want)
+			}
+		}
+	}
+}
+
+func TestUniformBigInt_LargeRangeIsUniform(t *testing.T) {
+	// n = 3 * 2^70: the top bits pick one of three equal thirds.
+	n := new(big.Int).Lsh(big.NewInt(3), 70)
+	third := new(big.Int).Lsh(big.NewInt(1), 70)
+	r := &xorshift32{x: 0xFACADE}
+
+	counts := make([]int, 3)
+	const draws = 90000
+	for i := 0; i < draws; i++ {
+		v, err := rng.UniformBigInt(r, nil, n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if v.Sign() < 0 || v.Cmp(n) >= 0 {
+			t.Fatalf("out of range: %s", v)
+		}
+		counts[new(big.Int).Div(v, third).Int64()]++
+	}
+	if chi := chiSquare(counts, draws/3.0); chi > 20 {
+		t.Fatalf("chi-square too large: %.2f (%v)", chi, counts)
+	}
+}
@@ -0,0 +1,177 @@
+package rng_test
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestRegex_GeneratesMatches(t *testing.T) {
+	patterns := []string{
+		`[A-Z]{3}-\d{4}`,
+		`^(foo|bar|baz)+$`,
+		`\Aa*b+c?d{2,}\z`,
+		`(?i)hello [^a-z]\w\s.`,
+		`[\p{Greek}]{2}x{0,3}`,
+		`(^a|b)c$`,
+		`(?:ab){2}|c`,
+	}
+
+	r := &xorshift32{x: 0x5E6E}
+	for _, pattern := range patterns {
+		re, err := rng.ParseRegex(pattern, 5)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", pattern, err)
+		}
+		check := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		for i := 0; i < 200; i++ {
+			s, err := re.Generate(r, nil)
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %v", pattern, err)
+			}
+			if !check.MatchString(s) {
+				t.Fatalf("%q generated non-matching %q", pattern, s)
+			}
+		}
+	}
+}
+
+func TestRegex_OpenClassesArePrintableASCII(t *testing.T) {
+	re, err := rng.ParseRegex(`[^a-z]{50}.{50}`, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := &xorshift32{x: 0xA5C11}
+	for i := 0; i < 50; i++ {
+		s, err := re.Generate(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, ch := range s {
+			if ch < 0x20 || ch > 0x7E {
+				t.Fatalf("non-printable rune %q in %q", ch, s)
+			}
+		}
+	}
+}
+
+func TestRegex_UnboundedRepeatRespectsMax(t *testing.T) {
+	re, err := rng.ParseRegex(`a*`, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := &xorshift32{x: 0x3}
+	seen := map[int]bool{}
+	for i := 0; i < 500; i++ {
+		s, err := re.Generate(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen[len(s)] = true
+	}
+	if len(seen) != 4 || !seen[0] || !seen[3] {
+		t.Fatalf("unexpected lengths %v", seen)
+	}
+}
+
+func TestRegex_AlternationUniform(t *testing.T) {
+	re, err := rng.ParseRegex(`a|b|c|d|e`, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := &xorshift32{x: 0xA17}
+	const draws = 10000
+	counts := make([]int, 5)
+	for i := 0; i < draws; i++ {
+		s, err := re.Generate(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts[s[0]-'a']++
+	}
+	// df = 4, p≈0.001 critical value ≈ 18.5.
+	if chi := chiSquare(counts, draws/5.0); chi > 18.5 {
+		t.Fatalf("chi-square too high: %.2f", chi)
+	}
+}
+
+func TestRegex_SharedPrefixAlternationUniform(t *testing.T) {
+	// syntax.Parse would factor these into foo|ba[rz], x(?:a[bc]|b) and ab[cd]|x.
+	for _, tt := range []struct {
+		pattern string
+		want    []string
+	}{
+		{`foo|bar|baz`, []string{"foo", "bar", "baz"}},
+		{`x(?:ab|ac|b)`, []string{"xab", "xac", "xb"}},
+		{`abc|abd|x`, []string{"abc", "abd", "x"}},
+		// Group and alternation characters inside quotes and classes are literal.
+		{`\Q(|\E|[|]|x`, []string{"(|", "|", "x"}},
+	} {
+		re, err := rng.ParseRegex(tt.pattern, 0)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", tt.pattern, err)
+		}
+		r := &xorshift32{x: 0xBA2}
+		const draws = 9000
+		counts := make([]int, len(tt.want))
+		for i := 0; i < draws; i++ {
+			s, err := re.Generate(r, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			idx := slices.Index(tt.want, s)
+			if idx < 0 {
+				t.Fatalf("%q: unexpected output %q", tt.pattern, s)
+			}
+			counts[idx]++
+		}
+		// df = 2, p≈0.001 critical value ≈ 13.8.
+		if chi := chiSquare(counts, draws/3.0); chi > 13.8 {
+			t.Fatalf("%q: chi-square too high: %.2f (%v)", tt.pattern, chi, counts)
+		}
+	}
+}
+
+func TestRegex_Errors(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{`(a)\1`, "backreferences"},
+		{`a(?=b)`, "lookaround"},
+		{`(?<!a)b`, "lookaround"},
+		{`a\bb`, "word boundaries"},
+		{`a^b`, "anchors"},
+		{`a$b`, "anchors"},
+		{`(a$)+`, "anchors"},
+		{`[^ -~]`, "no printable"},
+		{`(`, "invalid regular expression"},
+	}
+	for _, tt := range tests {
+		_, err := rng.ParseRegex(tt.pattern, 10)
+		if err == nil {
+			t.Fatalf("%q: expected error", tt.pattern)
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%q: error %q does not mention %q", tt.pattern, err, tt.want)
+		}
+	}
+
+	if _, err := rng.ParseRegex(`a`, 101); err == nil {
+		t.Fatalf("expected error for max repeat")
+	}
+}
+
+func TestRegex_TooLong(t *testing.T) {
+	re, err := rng.ParseRegex(strings.Repeat(`a{1000}`, 11), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := re.Generate(&xorshift32{x: 1}, nil); !errors.Is(err, rng.ErrRegexTooLong) {
+		t.Fatalf("expected ErrRegexTooLong, got %v", err)
+	}
+}
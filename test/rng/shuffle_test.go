@@ -0,0 +1,104 @@
+package rng_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestShuffle_AllPermutationsEquallyLikely(t *testing.T) {
+	r := &xorshift32{x: 0x0BADF00D}
+	counts := map[string]int{}
+
+	const draws = 240000
+	for i := 0; i < draws; i++ {
+		items := []string{"a", "b", "c", "d"}
+		if err := rng.Shuffle(r, nil, items); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		counts[strings.Join(items, "")]++
+	}
+
+	if len(counts) != 24 {
+		t.Fatalf("expected 24 distinct orderings, got %d", len(counts))
+	}
+
+	hist := make([]int, 0, len(counts))
+	for _, c := range counts {
+		hist = append(hist, c)
+	}
+	// 23 degrees of freedom; 60 is far beyond the 99.9th percentile.
+	if chi := chiSquare(hist, draws/24.0); chi > 60 {
+		t.Fatalf("chi-square too large: %.2f", chi)
+	}
+}
+
+func TestPartialShuffle_AllOrderedPicksEquallyLikely(t *testing.T) {
+	r := &xorshift32{x: 0x5EED1234}
+	counts := map[[3]int]int{}
+
+	const n, draws = 5, 300000
+	for i := 0; i < draws; i++ {
+		picked, err := rng.PartialShuffle(r, nil, n, 3)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if picked[0] == picked[1] || picked[0] == picked[2] || picked[1] == picked[2] {
+			t.Fatalf("repeated index in %v", picked)
+		}
+		counts[[3]int{picked[0], picked[1], picked[2]}]++
+	}
+
+	// 5 * 4 * 3 ordered picks.
+	if len(counts) != 60 {
+		t.Fatalf("expected 60 distinct ordered picks, got %d", len(counts))
+	}
+	hist := make([]int, 0, len(counts))
+	for _, c := range counts {
+		hist = append(hist, c)
+	}
+	// 59 degrees of freedom; 110 is far beyond the 99.9th percentile.
+	if chi := chiSquare(hist, draws/60.0); chi > 110 {
+		t.Fatalf("chi-square too large: %.2f", chi)
+	}
+
+	all, err := rng.PartialShuffle(r, nil, 1000, 1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sort.Ints(all)
+	for i, v := range all {
+		if v != i {
+			t.Fatalf("full partial shuffle is not a permutation at %d: %d", i, v)
+		}
+	}
+	if _, err := rng.PartialShuffle(r, nil, 3, 4); err == nil {
+		t.Fatalf("expected error for k > n")
+	}
+}
+
+func TestSequence_IsPermutationOfRange(t *testing.T) {
+	r := &uint32CounterReader{next: 7}
+
+	seq, err := rng.Sequence(r, nil, -3, 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seq) != 16 {
+		t.Fatalf("got %d values want 16", len(seq))
+	}
+
+	sorted := append([]int(nil), seq...)
+	sort.Ints(sorted)
+	for i, v := range sorted {
+		if v != i-3 {
+			t.Fatalf("sequence %v is not a permutation of [-3, 12]", seq)
+		}
+	}
+
+	if _, err := rng.Sequence(r, nil, 5, 4); err == nil {
+		t.Fatalf("expected error for min > max")
+	}
+}
@@ -0,0 +1,122 @@
+package rng_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/lost-woods/random/src/rng"
+)
+
+func TestFilterCharset_DedupesAndExcludes(t *testing.T) {
+	got := string(rng.FilterCharset([]rune("aabcé0O1lIé"), "b"+rng.AmbiguousChars))
+	if got != "acé" {
+		t.Fatalf("got %q want %q", got, "acé")
+	}
+}
+
+func TestClassOf(t *testing.T) {
+	for ch, want := range map[rune]int{
+		'a': rng.ClassLower, 'é': rng.ClassLower, 'Z': rng.ClassUpper,
+		'7': rng.ClassDigit, '!': rng.ClassSymbol, '€': rng.ClassSymbol,
+		'漢': rng.ClassOther,
+	} {
+		if got := rng.ClassOf(ch); got != want {
+			t.Fatalf("ClassOf(%q) = %d want %d", ch, got, want)
+		}
+	}
+}
+
+func TestStringSampler_Errors(t *testing.T) {
+	tests := []struct {
+		charset string
+		length  int
+		min     [rng.NumClasses]int
+	}{
+		{"", 4, [rng.NumClasses]int{}},
+		{"abc", 0, [rng.NumClasses]int{}},
+		{"abc", 4, [rng.NumClasses]int{rng.ClassDigit: 1}},
+		{"abc123", 4, [rng.NumClasses]int{rng.ClassLower: 3, rng.ClassDigit: 2}},
+	}
+	for _, tt := range tests {
+		if _, err := rng.NewStringSampler([]rune(tt.charset), tt.length, tt.min); err == nil {
+			t.Fatalf("expected error for %q length %d min %v", tt.charset, tt.length, tt.min)
+		}
+	}
+}
+
+// With charset "ab1", length 3 and at least one digit there are
+// 27 - 8 = 19 valid strings; each must be equally likely.
+func TestStringSampler_UniformOverValidStrings(t *testing.T) {
+	s, err := rng.NewStringSampler([]rune("ab1"), 3, [rng.NumClasses]int{rng.ClassDigit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.EntropyBits(); math.Abs(got-math.Log2(19)) > 1e-9 {
+		t.Fatalf("entropy %v want %v", got, math.Log2(19))
+	}
+
+	r := &xorshift32{x: 0x57A1}
+	const draws = 19 * 2000
+	seen := map[string]int{}
+	for i := 0; i < draws; i++ {
+		str, err := s.Generate(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		seen[str]++
+	}
+	if len(seen) != 19 {
+		t.Fatalf("saw %d distinct strings want 19", len(seen))
+	}
+
+	counts := make([]int, 0, len(seen))
+	for str, n := range seen {
+		hasDigit := false
+		for _, ch := range str {
+			hasDigit = hasDigit || ch == '1'
+		}
+		if !hasDigit {
+			t.Fatalf("string %q has no digit", str)
+		}
+		counts = append(counts, n)
+	}
+	// df = 18, p≈0.001 critical value ≈ 42.3.
+	if chi := chiSquare(counts, draws/19.0); chi > 42.3 {
+		t.Fatalf("chi-square too high: %.2f", chi)
+	}
+}
+
+func TestStringSampler_MinimumsAlwaysMet(t *testing.T) {
+	charset := rng.FilterCharset([]rune(string(rng.BuildCharset(true, true, true, true))+"漢字"), "")
+	min := [rng.NumClasses]int{rng.ClassLower: 2, rng.ClassUpper: 2, rng.ClassDigit: 3, rng.ClassSymbol: 1}
+	s, err := rng.NewStringSampler(charset, 8, min)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := &xorshift32{x: 0xC1A55}
+	for i := 0; i < 500; i++ {
+		str, err := s.Generate(r, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got [rng.NumClasses]int
+		for _, ch := range str {
+			got[rng.ClassOf(ch)]++
+		}
+		if len([]rune(str)) != 8 || got[rng.ClassLower] < 2 || got[rng.ClassUpper] < 2 ||
+			got[rng.ClassDigit] < 3 || got[rng.ClassSymbol] < 1 {
+			t.Fatalf("string %q violates minimums", str)
+		}
+	}
+}
+
+func TestStringSampler_NoMinimumsEntropy(t *testing.T) {
+	s, err := rng.NewStringSampler([]rune("0123456789abcdef"), 10, [rng.NumClasses]int{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := s.EntropyBits(); got != 40 {
+		t.Fatalf("entropy %v want 40", got)
+	}
+}